@@ -1,6 +1,7 @@
 package main
 
 import (
+	"embed"
 	"encoding/json"
 	"net/http"
 	"time"
@@ -9,6 +10,11 @@ import (
 	"go.uber.org/zap"
 )
 
+// Arquivos estáticos embutidos no binário
+//
+//go:embed static
+var staticFiles embed.FS
+
 // ChatMessage representa uma mensagem de chat
 type ChatMessage struct {
 	Type      string    `json:"type"`
@@ -65,24 +71,12 @@ func main() {
 
 	// Servir arquivos estáticos
 	app.GET("/", func(c *hikari.Context) {
-		c.File("./static/index.html")
+		c.FileFS(staticFiles, "static/index.html")
 	})
 
 	app.GET("/static/*", func(c *hikari.Context) {
-		filePath := c.Wildcard()
-		var contentType string
-		switch {
-		case filePath[len(filePath)-4:] == ".css":
-			contentType = "text/css"
-		case filePath[len(filePath)-3:] == ".js":
-			contentType = "application/javascript"
-		case filePath[len(filePath)-5:] == ".html":
-			contentType = "text/html"
-		default:
-			contentType = "application/octet-stream"
-		}
-		c.SetHeader("Content-Type", contentType)
-		c.File("./static/" + filePath)
+		c.SetHeader("Cache-Control", "public, max-age=3600")
+		c.FileFS(staticFiles, "static/"+c.Wildcard())
 	})
 
 	// API Routes
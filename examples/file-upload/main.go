@@ -467,8 +467,8 @@ func serveStatic(c *hikari.Context) {
 		return
 	}
 
-	// Serve the file
-	c.File(fullPath)
+	// Serve the file, letting browsers cache it for a day
+	c.FileWithCache(fullPath, 24*time.Hour)
 }
 
 func healthCheck(c *hikari.Context) {
@@ -65,6 +65,7 @@ func main() {
 		{
 			todosGroup.GET("/", getTodos)
 			todosGroup.POST("/", createTodo)
+			todosGroup.POST("/bulk", createTodos)
 			todosGroup.GET("/:id", getTodo)
 			todosGroup.PUT("/:id", updateTodo)
 			todosGroup.DELETE("/:id", deleteTodo)
@@ -100,6 +101,7 @@ func homePage(c *hikari.Context) {
 			"GET /api/v1/todos":              "List all todos",
 			"GET /api/v1/todos/:id":          "Get todo by ID",
 			"POST /api/v1/todos":             "Create new todo",
+			"POST /api/v1/todos/bulk":        "Create up to 50 todos at once",
 			"PUT /api/v1/todos/:id":          "Update todo",
 			"DELETE /api/v1/todos/:id":       "Delete todo",
 			"PATCH /api/v1/todos/:id/toggle": "Toggle todo completion",
@@ -189,6 +191,49 @@ func createTodo(c *hikari.Context) {
 	c.JSON(http.StatusCreated, todo)
 }
 
+func createTodos(c *hikari.Context) {
+	var newTodos []struct {
+		Title   string `json:"title"`
+		Content string `json:"content"`
+	}
+
+	if err := c.BindSlice(&newTodos, 50); err != nil {
+		c.JSON(http.StatusBadRequest, hikari.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	for _, newTodo := range newTodos {
+		if newTodo.Title == "" {
+			c.JSON(http.StatusBadRequest, hikari.H{
+				"error": "Title is required",
+			})
+			return
+		}
+	}
+
+	created := make([]Todo, 0, len(newTodos))
+	for _, newTodo := range newTodos {
+		todo := Todo{
+			ID:        nextID,
+			Title:     newTodo.Title,
+			Content:   newTodo.Content,
+			Completed: false,
+			CreatedAt: time.Now(),
+		}
+
+		todos = append(todos, todo)
+		created = append(created, todo)
+		nextID++
+	}
+
+	c.JSON(http.StatusCreated, hikari.H{
+		"todos": created,
+		"count": len(created),
+	})
+}
+
 func updateTodo(c *hikari.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -2,23 +2,91 @@ package hikari
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
+	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
 type App struct {
 	addr        string
+	listener    net.Listener
+	listenerMu  sync.RWMutex
 	router      *router
 	middlewares []Middleware
 	server      *http.Server
 	logger      *zap.Logger
 
 	requestTimeout time.Duration
+	maxBodySize    int64
+
+	// Paths served without the request timeout, set by ExemptFromTimeout
+	timeoutExempt []*route
+	// Methods served without the request timeout, set by ExemptMethodsFromTimeout
+	timeoutExemptMethods map[string]bool
+
+	// Limits on the request path, 0 disables them
+	maxPathLength   int
+	maxPathSegments int
+
+	// Guards against deeply nested or huge JSON bodies in Bind
+	maxJSONDepth    int
+	maxJSONElements int
+
+	// Custom response for request bodies exceeding maxBodySize
+	bodyTooLargeHandler HandlerFunc
+	errorHandler        ErrorHandler
+	errorEnvelope       ErrorEnvelope
+
+	// Built-in middlewares that were explicitly disabled
+	loggerDisabled   bool
+	recoveryDisabled bool
+
+	// Custom response for recovered panics
+	recoveryHandler RecoveryHandler
+
+	// Proxies and platform header trusted by Context.ClientIP
+	trustedProxies  []*net.IPNet
+	trustedPlatform string
+
+	// Print the route table at startup instead of one log line per route
+	printRoutes bool
+
+	// Only log requests slower than this or failing, 0 logs every request
+	slowRequestThreshold time.Duration
+
+	// Record the Named middlewares run by each request for Context.MiddlewareTrace
+	middlewareTrace bool
+	// Time the Named middlewares run by each request for Context.MiddlewareTimings
+	middlewareProfiling bool
+
+	// Build identification set by SetInfo, sent as headers if infoHeaders is on
+	name        string
+	version     string
+	infoHeaders bool
+
+	// Templates rendered by Context.HTML
+	templates *template.Template
+
+	// Log /favicon.ico 404s at debug level instead of warn
+	quietFavicon bool
+
+	// Set by Drain, makes the readiness check report 503 while requests keep being served
+	draining atomic.Bool
+	// Time between draining on SIGTERM and shutting down, 0 shuts down at once
+	drainGrace time.Duration
 
 	wsManager *WebSocketManager
 }
@@ -33,11 +101,13 @@ func New(addr string) *App {
 	logger, _ := config.Build()
 
 	return &App{
-		addr:           addr,
-		router:         newRouter(logger),
-		middlewares:    []Middleware{},
-		logger:         logger,
-		requestTimeout: 30 * time.Second, // Default request timeout
+		addr:            addr,
+		router:          newRouter(logger),
+		middlewares:     []Middleware{},
+		logger:          logger,
+		requestTimeout:  30 * time.Second, // Default request timeout
+		maxJSONDepth:    defaultMaxJSONDepth,
+		maxJSONElements: defaultMaxJSONElements,
 		server: &http.Server{
 			Addr:         addr,
 			ReadTimeout:  5 * time.Second,
@@ -48,13 +118,15 @@ func New(addr string) *App {
 
 func (a *App) buildHandler() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-		isWebSocket := a.wsManager != nil && req.Header.Get("Upgrade") == "websocket" && req.Header.Get("Connection") == "Upgrade"
+		// A missed upgrade would get the request timeout and cancel the
+		// connection context shared by message handlers
+		isWebSocket := a.wsManager != nil && isWebSocketRequest(req)
 
 		var reqCtx context.Context
 		var cancel context.CancelFunc
 
-		if isWebSocket {
-			// For WebSocket requests, use the request's context without timeout
+		if isWebSocket || a.isTimeoutExempt(req) {
+			// For WebSocket and exempt requests, use the request's context without timeout
 			reqCtx = req.Context()
 			cancel = func() {}
 		} else {
@@ -63,23 +135,47 @@ func (a *App) buildHandler() http.Handler {
 		}
 		defer cancel()
 
+		// Oversized paths are rejected before any route matching work
+		uriTooLong := a.exceedsPathLimits(req.URL.Path)
+
 		routerHandler := func(c *Context) {
+			if uriTooLong {
+				c.Error(http.StatusRequestURITooLong, errors.New("URI too long"))
+				return
+			}
 			a.router.serveContext(c)
 		}
 
 		// Apply middlewares in reverse order to achieve correct execution order:
-		// Execution flow: Recovery -> Logger -> User Middlewares -> Handler
-		handler := routerHandler
-
-		// Apply user middlewares first (in reverse order)
-		for i := len(a.middlewares) - 1; i >= 0; i-- {
-			handler = a.middlewares[i](handler)
-		}
+		// Execution flow: Recovery -> Logger -> User Middlewares (Use) -> Routing ->
+		// Post-routing Middlewares (UsePost) -> Route Middlewares -> Handler
+		//
+		// Middlewares registered with Use run for every request, even when no route
+		// matches. Middlewares registered with UsePost only run for matched routes.
+		// Apply user middlewares first
+		handler := chain(routerHandler, a.middlewares)
 
 		// Apply built-in middlewares (logger wraps user middlewares)
-		handler = a.loggerMiddleware(handler)
+		if !a.loggerDisabled {
+			handler = a.loggerMiddleware(handler)
+		}
 		// Recovery wraps everything (outermost layer)
-		handler = a.recoveryMiddleware(handler)
+		if !a.recoveryDisabled {
+			handler = a.recoveryMiddleware(handler)
+		}
+
+		if a.infoHeaders {
+			if a.name != "" {
+				w.Header().Set("X-App-Name", a.name)
+			}
+			if a.version != "" {
+				w.Header().Set("X-App-Version", a.version)
+			}
+		}
+
+		if a.maxBodySize > 0 {
+			req.Body = http.MaxBytesReader(w, req.Body, a.maxBodySize)
+		}
 
 		// Create context and call the handler
 		ctx := &Context{
@@ -90,13 +186,78 @@ func (a *App) buildHandler() http.Handler {
 
 			Context: reqCtx,
 			storage: make(map[string]interface{}),
+
+			app:          a,
+			bodyTooLarge: a.bodyTooLargeHandler,
+			errorHandler: a.errorHandler,
 		}
 
 		handler(ctx)
 	})
 }
 
+// ListenAndServe starts the server and blocks until an interrupt signal is
+// received. It panics on server or shutdown errors; use Run or RunContext to
+// handle errors instead.
 func (a *App) ListenAndServe() {
+	if err := a.Run(); err != nil {
+		panic(err)
+	}
+}
+
+// Run starts the server and blocks until SIGINT or SIGTERM is received, then
+// shuts it down gracefully. With DrainOnSIGTERM, SIGTERM drains the app before
+// shutting down.
+func (a *App) Run() error {
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	go func() {
+		a.waitForSignals(ctx, signals)
+		stop()
+	}()
+
+	return a.RunContext(ctx)
+}
+
+// waitForSignals returns once the server should shut down: on the first signal,
+// or after the drain grace period when DrainOnSIGTERM is set and the signal is
+// SIGTERM. A second signal during the grace period ends it early.
+func (a *App) waitForSignals(ctx context.Context, signals <-chan os.Signal) {
+	var sig os.Signal
+	select {
+	case sig = <-signals:
+	case <-ctx.Done():
+		return
+	}
+	if sig != syscall.SIGTERM || a.drainGrace <= 0 {
+		return
+	}
+
+	a.Drain()
+	grace := time.NewTimer(a.drainGrace)
+	defer grace.Stop()
+
+	select {
+	case <-grace.C:
+	case <-signals:
+		a.logger.Warn("Second signal received, shutting down before the drain grace period ends")
+	case <-ctx.Done():
+	}
+}
+
+// RunContext starts the server and blocks until ctx is cancelled, then shuts it
+// down gracefully. Server and shutdown errors are returned instead of panicking.
+func (a *App) RunContext(ctx context.Context) error {
+	if err := a.validate(); err != nil {
+		a.logger.Error("Invalid app configuration", zap.Error(err))
+		return err
+	}
+
 	// Set the handler with middlewares applied
 	a.server.Handler = a.buildHandler()
 
@@ -105,91 +266,337 @@ func (a *App) ListenAndServe() {
 		zap.String("address", a.addr),
 	)
 
-	for _, route := range a.router.routes {
-		a.logger.Info("HTTP route configured",
-			zap.String("method", route.method),
-			zap.String("pattern", route.pattern),
-		)
+	if a.recoveryDisabled {
+		a.logger.Warn("Built-in recovery middleware is disabled, panics in handlers will not be recovered")
 	}
 
-	// Listing all configured WebSocket and HTTP routes
-	if a.wsManager != nil {
-		for hubName := range a.wsManager.hubs {
-			a.logger.Info("WebSocket Hub configured",
-				zap.String("hub", hubName),
-			)
-		}
+	a.logRoutes()
+	a.router.warnAmbiguous()
+
+	// Bind before serving so the actual address is known when addr uses port 0
+	ln, err := net.Listen("tcp", a.addr)
+	if err != nil {
+		a.logger.Error("Server error", zap.Error(err))
+		return err
 	}
+	a.listenerMu.Lock()
+	a.listener = ln
+	a.listenerMu.Unlock()
+
+	a.logger.Info("HTTP server listening", zap.String("address", ln.Addr().String()))
 
 	// Channel to receive server errors
 	serverErr := make(chan error, 1)
 
 	// Start the server in a goroutine
 	go func() {
-		if err := a.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := a.server.Serve(ln); err != nil && err != http.ErrServerClosed {
 			serverErr <- err
 		}
 	}()
 
-	// Channel to listen for interrupt signals
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt)
-
-	// Wait for either an error or interrupt signal
+	// Wait for either an error or context cancellation
 	select {
 	case err := <-serverErr:
 		a.logger.Error("Server error", zap.Error(err))
-		panic(err)
-	case <-stop:
+		return err
+	case <-ctx.Done():
 		a.logger.Info("Shutdown signal received, gracefully stopping server...")
 	}
 
 	// Create a deadline for shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	if err := a.Shutdown(ctx); err != nil {
+	if err := a.Shutdown(shutdownCtx); err != nil {
 		a.logger.Error("Server shutdown error", zap.Error(err))
-		panic(err)
+		return err
 	}
 
 	a.logger.Info("Server stopped gracefully")
+	return nil
+}
+
+// validate checks the configuration before the server starts, so mistakes fail
+// at startup instead of on the first request.
+func (a *App) validate() error {
+	if a.wsManager == nil {
+		for _, rt := range a.router.routes {
+			if rt.hub != "" {
+				return fmt.Errorf("%w: WebSocket route %s uses hub %q", ErrWebSocketNotConfigured, rt.pattern, rt.hub)
+			}
+		}
+	}
+	return nil
+}
+
+// Addr returns the address the server is listening on. Before the server has
+// been started it returns the address passed to New.
+func (a *App) Addr() string {
+	a.listenerMu.RLock()
+	defer a.listenerMu.RUnlock()
+
+	if a.listener == nil {
+		return a.addr
+	}
+	return a.listener.Addr().String()
 }
 
 func (a *App) Shutdown(ctx context.Context) error {
 	defer a.logger.Sync() // Flush any remaining logs
+
+	// Hijacked WebSocket connections are not tracked by http.Server, close them explicitly
+	if a.wsManager != nil {
+		a.wsManager.closeAll(ctx, websocket.CloseGoingAway, "server shutting down")
+		a.wsManager.shutdown()
+	}
+
 	return a.server.Shutdown(ctx)
 }
 
+// Drain puts the app in draining mode: the readiness check starts returning 503
+// so load balancers stop routing new traffic, keep-alive connections are no
+// longer reused, and in-flight requests keep being served until Shutdown.
+func (a *App) Drain() {
+	if a.draining.Swap(true) {
+		return
+	}
+	a.server.SetKeepAlivesEnabled(false)
+	a.logger.Info("Draining server, readiness check will report unavailable")
+}
+
+// DrainOnSIGTERM makes Run drain the app when SIGTERM is received and shut it
+// down once grace has elapsed, so load balancers notice the readiness check
+// failing before the server stops. A second SIGTERM or SIGINT shuts it down
+// right away. By default SIGTERM shuts down immediately like SIGINT.
+func (a *App) DrainOnSIGTERM(grace time.Duration) {
+	a.drainGrace = grace
+}
+
+func (a *App) IsDraining() bool {
+	return a.draining.Load()
+}
+
+// Readiness registers a readiness check at pattern that returns 200 while the
+// app is serving traffic and 503 once Drain has been called.
+func (a *App) Readiness(pattern string, middlewares ...Middleware) *Route {
+	return a.GET(pattern, func(c *Context) {
+		if a.IsDraining() {
+			c.JSON(http.StatusServiceUnavailable, H{"status": "draining"})
+			return
+		}
+		c.JSON(http.StatusOK, H{"status": "ready"})
+	}, middlewares...)
+}
+
+// SetInfo sets the app's name and version, e.g. the build's git tag. They are
+// only sent to clients if SetInfoHeaders is enabled.
+func (a *App) SetInfo(name, version string) {
+	a.name = name
+	a.version = version
+}
+
+func (a *App) Name() string {
+	return a.name
+}
+
+func (a *App) Version() string {
+	return a.version
+}
+
+// SetInfoHeaders adds the X-App-Name and X-App-Version headers set by SetInfo
+// to every response. Off by default to avoid disclosing the version.
+func (a *App) SetInfoHeaders(enabled bool) {
+	a.infoHeaders = enabled
+}
+
+// Favicon serves the file at filePath on /favicon.ico with long cache headers.
+func (a *App) Favicon(filePath string) *Route {
+	return a.GET("/favicon.ico", func(c *Context) {
+		c.SetHeader("Cache-Control", "public, max-age=31536000, immutable")
+		c.File(filePath)
+	})
+}
+
+// LogSlowerThan makes the logger middleware skip the "Request started" line and
+// only log the completion of requests taking longer than d or responding with
+// a status >= 400. Every request is logged by default, 0 restores it.
+func (a *App) LogSlowerThan(d time.Duration) {
+	a.slowRequestThreshold = d
+}
+
+// SetQuietFavicon makes the logger middleware log /favicon.ico 404s at debug
+// level instead of warn.
+func (a *App) SetQuietFavicon(quiet bool) {
+	a.quietFavicon = quiet
+}
+
+// DisableLogger removes the built-in request logger middleware.
+func (a *App) DisableLogger() {
+	a.loggerDisabled = true
+}
+
+// DisableRecovery removes the built-in panic recovery middleware. Only use it
+// when another middleware takes care of recovering from panics.
+func (a *App) DisableRecovery() {
+	a.recoveryDisabled = true
+}
+
+// SetBasePath prefixes every route registered afterwards with basePath, for
+// apps served under a sub-path such as /myapp. Groups and WebSocket routes are
+// prefixed too, so call it before registering any route.
+func (a *App) SetBasePath(basePath string) {
+	if basePath == "" || basePath == "/" {
+		a.router.basePath = ""
+		return
+	}
+	a.router.basePath = buildPattern("", basePath, a.logger)
+}
+
+// SetRecoveryHandler sets the function writing the response when a handler
+// panics, to customize its status and body. By default a 500 is written in the
+// format set by SetDefaultResponseFormat.
+func (a *App) SetRecoveryHandler(handler RecoveryHandler) {
+	a.recoveryHandler = handler
+}
+
+// NotFound sets the handler called when no route matches the request path.
+func (a *App) NotFound(handler HandlerFunc) {
+	a.router.notFound = handler
+}
+
+// MethodNotAllowed sets the handler called when a route matches the request
+// path but not its method. The Allow header is already set when it runs.
+func (a *App) MethodNotAllowed(handler HandlerFunc) {
+	a.router.methodNotAllowed = handler
+}
+
+// SetDefaultResponseFormat sets the format of the default 404, 405 and panic
+// responses. ResponseFormatText is used unless changed.
+func (a *App) SetDefaultResponseFormat(format ResponseFormat) {
+	a.router.responseFormat = format
+}
+
 func (a *App) SetRequestTimeout(d time.Duration) {
 	a.requestTimeout = d
 }
 
+// ExemptFromTimeout serves requests whose path matches one of patterns, such as
+// "/events" or "/api/v1/download/*", without the app's request timeout, for SSE
+// and long downloads. Patterns use the route syntax and are prefixed with the
+// base path like routes. See also ExemptMethodsFromTimeout.
+func (a *App) ExemptFromTimeout(patterns ...string) {
+	for _, pattern := range patterns {
+		fullPattern := buildPattern(a.router.basePath, pattern, a.logger)
+		a.timeoutExempt = append(a.timeoutExempt, &route{pattern: fullPattern, parts: splitPath(fullPattern)})
+	}
+}
+
+// ExemptMethodsFromTimeout serves every request with one of methods, e.g.
+// "GET" for long-polling conventions, without the app's request timeout. It
+// adds to ExemptFromTimeout: a request matching either exemption is untimed.
+func (a *App) ExemptMethodsFromTimeout(methods ...string) {
+	if a.timeoutExemptMethods == nil {
+		a.timeoutExemptMethods = make(map[string]bool, len(methods))
+	}
+	for _, method := range methods {
+		a.timeoutExemptMethods[strings.ToUpper(method)] = true
+	}
+}
+
+func (a *App) isTimeoutExempt(req *http.Request) bool {
+	if a.timeoutExemptMethods[req.Method] {
+		return true
+	}
+	if len(a.timeoutExempt) == 0 {
+		return false
+	}
+	rParts := splitPath(normalizedPattern(req.URL.Path))
+	for _, rt := range a.timeoutExempt {
+		if rt.match(rParts) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetMaxHeaderBytes limits the size of the request line and headers to about n
+// bytes, http.DefaultMaxHeaderBytes (1 MB) by default. Larger requests are
+// answered by net/http with 431 Request Header Fields Too Large before
+// reaching the handler, so middlewares and the error handler don't run for them.
+func (a *App) SetMaxHeaderBytes(n int) {
+	a.server.MaxHeaderBytes = n
+}
+
+// SetMaxPathLength rejects requests whose path is longer than n bytes with
+// 414 URI Too Long. A value of 0 disables the limit.
+func (a *App) SetMaxPathLength(n int) {
+	a.maxPathLength = n
+}
+
+// SetMaxPathSegments rejects requests whose path has more than n segments with
+// 414 URI Too Long, bounding the work done by the router. A value of 0
+// disables the limit.
+func (a *App) SetMaxPathSegments(n int) {
+	a.maxPathSegments = n
+}
+
+func (a *App) exceedsPathLimits(path string) bool {
+	if a.maxPathLength > 0 && len(path) > a.maxPathLength {
+		return true
+	}
+	return a.maxPathSegments > 0 && strings.Count(path, "/") > a.maxPathSegments
+}
+
+// SetMaxBodySize limits the number of bytes read from request bodies.
+// A value of 0 disables the limit.
+func (a *App) SetMaxBodySize(n int64) {
+	a.maxBodySize = n
+}
+
+// SetErrorHandler replaces the default JSON error response used by
+// Context.Error, MustBind and the 413 response.
+func (a *App) SetErrorHandler(handler ErrorHandler) {
+	a.errorHandler = handler
+}
+
+// SetErrorEnvelope sets the shape of the JSON body written by JSONError and
+// the default error handler. ErrorEnvelopeFlat is used unless changed.
+func (a *App) SetErrorEnvelope(envelope ErrorEnvelope) {
+	a.errorEnvelope = envelope
+}
+
+// SetBodyTooLargeHandler replaces the 413 response written through the error
+// handler when a request body exceeds the max body size.
+func (a *App) SetBodyTooLargeHandler(handler HandlerFunc) {
+	a.bodyTooLargeHandler = handler
+}
+
 func (a *App) WithWebSocket(config *WebSocketConfig) {
 	a.wsManager = NewWebSocketManager(config, a.logger)
 }
 
-func (a *App) GET(pattern string, handler HandlerFunc, middlewares ...Middleware) {
-	a.router.handle(http.MethodGet, pattern, handler, middlewares...)
+func (a *App) GET(pattern string, handler HandlerFunc, middlewares ...Middleware) *Route {
+	return a.router.handle(http.MethodGet, pattern, handler, middlewares...)
 }
 
-func (a *App) POST(pattern string, handler HandlerFunc, middlewares ...Middleware) {
-	a.router.handle(http.MethodPost, pattern, handler, middlewares...)
+func (a *App) POST(pattern string, handler HandlerFunc, middlewares ...Middleware) *Route {
+	return a.router.handle(http.MethodPost, pattern, handler, middlewares...)
 }
 
-func (a *App) PUT(pattern string, handler HandlerFunc, middlewares ...Middleware) {
-	a.router.handle(http.MethodPut, pattern, handler, middlewares...)
+func (a *App) PUT(pattern string, handler HandlerFunc, middlewares ...Middleware) *Route {
+	return a.router.handle(http.MethodPut, pattern, handler, middlewares...)
 }
 
-func (a *App) PATCH(pattern string, handler HandlerFunc, middlewares ...Middleware) {
-	a.router.handle(http.MethodPatch, pattern, handler, middlewares...)
+func (a *App) PATCH(pattern string, handler HandlerFunc, middlewares ...Middleware) *Route {
+	return a.router.handle(http.MethodPatch, pattern, handler, middlewares...)
 }
 
-func (a *App) DELETE(pattern string, handler HandlerFunc, middlewares ...Middleware) {
-	a.router.handle(http.MethodDelete, pattern, handler, middlewares...)
+func (a *App) DELETE(pattern string, handler HandlerFunc, middlewares ...Middleware) *Route {
+	return a.router.handle(http.MethodDelete, pattern, handler, middlewares...)
 }
 
-func (a *App) WebSocket(path, hubName string, handler WebSocketHandler, middlewares ...Middleware) {
+func (a *App) WebSocket(path, hubName string, handler WebSocketHandler, middlewares ...Middleware) *Route {
 	if a.wsManager != nil {
 		a.wsManager.RegisterHub(hubName)
 	}
@@ -202,6 +609,10 @@ func (a *App) WebSocket(path, hubName string, handler WebSocketHandler, middlewa
 		}
 
 		err := a.wsManager.Upgrade(c, hubName, handler)
+		if errors.Is(err, ErrHubFull) {
+			c.Error(http.StatusServiceUnavailable, err)
+			return
+		}
 		if err != nil {
 			a.logger.Error("WebSocket upgrade failed", zap.Error(err))
 			c.JSON(http.StatusInternalServerError, map[string]string{"error": "WebSocket upgrade failed"})
@@ -209,7 +620,20 @@ func (a *App) WebSocket(path, hubName string, handler WebSocketHandler, middlewa
 		}
 	}
 
-	a.GET(path, wsHandler, middlewares...)
+	r := a.GET(path, wsHandler, middlewares...)
+	r.route.hub = hubName
+	return r
+}
+
+// WebSocketManager returns the app's WebSocket manager, to create, remove and
+// list hubs at runtime, such as rooms created on demand. It is initialized with
+// DefaultWebSocketConfig if WithWebSocket wasn't called. Its methods are safe to
+// call concurrently with serving requests.
+func (a *App) WebSocketManager() *WebSocketManager {
+	if a.wsManager == nil {
+		a.WithWebSocket(DefaultWebSocketConfig())
+	}
+	return a.wsManager
 }
 
 func (a *App) GetWebSocketHub(name string) (*WebSocketHub, bool) {
@@ -228,6 +652,38 @@ func (a *App) Group(prefix string, middlewares ...Middleware) *Group {
 	}
 }
 
-func (a *App) Use(middleware Middleware) {
-	a.middlewares = append(a.middlewares, middleware)
+// Use registers global middlewares, executed in the order given.
+func (a *App) Use(middlewares ...Middleware) {
+	a.middlewares = append(a.middlewares, middlewares...)
+}
+
+// SetMiddlewareTrace makes each request record the Named middlewares it runs,
+// read with Context.MiddlewareTrace. Off by default to avoid the overhead in
+// production.
+func (a *App) SetMiddlewareTrace(enabled bool) {
+	a.middlewareTrace = enabled
+}
+
+// EnableMiddlewareProfiling makes each request time its Named middlewares, read
+// with Context.MiddlewareTimings and logged with the request's completion by
+// the logger middleware. Off by default to avoid the overhead.
+func (a *App) EnableMiddlewareProfiling(enabled bool) {
+	a.middlewareProfiling = enabled
+}
+
+// UseFunc registers a global middleware written as a MiddlewareFunc:
+//
+//	app.UseFunc(func(c *hikari.Context, next func()) {
+//		start := time.Now()
+//		next()
+//		c.Logger.Info("took", zap.Duration("duration", time.Since(start)))
+//	})
+func (a *App) UseFunc(fn MiddlewareFunc) {
+	a.Use(fn.Middleware())
+}
+
+// UsePost registers middlewares that only run after a route has been matched,
+// before the route's own middlewares.
+func (a *App) UsePost(middlewares ...Middleware) {
+	a.router.postMiddlewares = append(a.router.postMiddlewares, middlewares...)
 }
@@ -0,0 +1,134 @@
+package hikari
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/goleak"
+)
+
+func TestShutdownStopsWebSocketGoroutines(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	app := newTestApp()
+	app.WithWebSocket(DefaultWebSocketConfig())
+	app.WebSocket("/ws/chat", "chat", func(c *WSContext) {})
+	app.WebSocket("/ws/news", "news", func(c *WSContext) {})
+
+	srv := httptest.NewServer(app.buildHandler())
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws/chat", nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := app.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	conn.Close()
+	srv.Close()
+}
+
+func TestPathLimitsRespond414(t *testing.T) {
+	app := newTestApp()
+	app.SetMaxPathSegments(32)
+	app.SetMaxPathLength(2048)
+
+	handled := false
+	app.GET("/files/*", func(c *Context) {
+		handled = true
+		c.String(http.StatusOK, "ok")
+	})
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"many segments", "/files" + strings.Repeat("/a", 5000), http.StatusRequestURITooLong},
+		{"long path", "/files/" + strings.Repeat("a", 4096), http.StatusRequestURITooLong},
+		{"within limits", "/files/a/b/c", http.StatusOK},
+	}
+	for _, tt := range tests {
+		handled = false
+		w := serve(app, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.want)
+		}
+		if handled != (tt.want == http.StatusOK) {
+			t.Errorf("%s: handler called = %v", tt.name, handled)
+		}
+	}
+}
+
+func TestRunFailsWithoutWebSocketManager(t *testing.T) {
+	app := newTestApp()
+	app.WebSocket("/ws", "chat", func(c *WSContext) {})
+
+	err := app.RunContext(context.Background())
+	if !errors.Is(err, ErrWebSocketNotConfigured) {
+		t.Fatalf("RunContext = %v, want ErrWebSocketNotConfigured", err)
+	}
+}
+
+func TestSIGTERMShutsDownByDefault(t *testing.T) {
+	app := newTestApp()
+	signals := make(chan os.Signal, 1)
+	signals <- syscall.SIGTERM
+
+	done := make(chan struct{})
+	go func() {
+		app.waitForSignals(context.Background(), signals)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("SIGTERM did not start the shutdown")
+	}
+	if app.IsDraining() {
+		t.Fatal("app drained without DrainOnSIGTERM")
+	}
+}
+
+func TestDrainOnSIGTERM(t *testing.T) {
+	for _, second := range []bool{false, true} {
+		app := newTestApp()
+		app.DrainOnSIGTERM(300 * time.Millisecond)
+		signals := make(chan os.Signal, 1)
+		signals <- syscall.SIGTERM
+
+		start := time.Now()
+		done := make(chan struct{})
+		go func() {
+			app.waitForSignals(context.Background(), signals)
+			close(done)
+		}()
+
+		waitFor(t, app.IsDraining)
+		if second {
+			// A second signal doesn't wait for the grace period
+			signals <- syscall.SIGTERM
+		}
+		<-done
+
+		elapsed := time.Since(start)
+		if second && elapsed >= 300*time.Millisecond {
+			t.Fatalf("second signal: shutdown after %v, want before the grace period", elapsed)
+		}
+		if !second && elapsed < 300*time.Millisecond {
+			t.Fatalf("shutdown after %v, want after the grace period", elapsed)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package hikari
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var errBindTarget = errors.New("bind target must be a non-nil pointer to a struct")
+
+// BindQuery binds the URL query string into v using the `query` struct tag.
+func (c *Context) BindQuery(v any) error {
+	return bindValues(v, "query", c.Request.URL.Query())
+}
+
+// BindParams binds the route parameters into v using the `param` struct tag.
+func (c *Context) BindParams(v any) error {
+	values := make(url.Values, len(c.Params))
+	for key, value := range c.Params {
+		values.Set(key, value)
+	}
+	return bindValues(v, "param", values)
+}
+
+// BindHeader binds the request headers into v using the `header` struct tag.
+func (c *Context) BindHeader(v any) error {
+	return bindSource(v, "header", c.Request.Header.Values)
+}
+
+// BindForm binds the parsed form values into v using the `form` struct tag.
+func (c *Context) BindForm(v any) error {
+	if err := c.Request.ParseForm(); err != nil {
+		return c.checkBodyTooLarge(err)
+	}
+	return bindValues(v, "form", c.Request.Form)
+}
+
+// BindAll binds every request source into v, in this order: the query string
+// (`query` tags), the route parameters (`param` tags), then the body, as JSON
+// or, for form content types, with `form` tags. A later source overrides an
+// earlier one for the same field, so the body wins over the query. A missing
+// body is not an error.
+func (c *Context) BindAll(v any) error {
+	if err := c.BindQuery(v); err != nil {
+		return err
+	}
+	if err := c.BindParams(v); err != nil {
+		return err
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.Request.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		return c.BindForm(v)
+	case "multipart/form-data":
+		return c.BindMultipart(v)
+	}
+	return c.BindOptional(v)
+}
+
+// multipartMemory is the part of a multipart body kept in memory by
+// BindMultipart, larger files are stored in temporary files. The whole body is
+// still limited by the app's max body size.
+const multipartMemory = 32 << 20
+
+var (
+	fileHeaderType      = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeaderSliceType = reflect.TypeOf([]*multipart.FileHeader(nil))
+)
+
+// BindMultipart binds a multipart form into v using the `form` struct tag.
+// Fields of type *multipart.FileHeader and []*multipart.FileHeader receive the
+// uploaded files, other fields the form values like BindForm.
+//
+//	var req struct {
+//		Title string                  `form:"title"`
+//		Cover *multipart.FileHeader   `form:"cover"`
+//		Pages []*multipart.FileHeader `form:"pages"`
+//	}
+func (c *Context) BindMultipart(v any) error {
+	if err := c.Request.ParseMultipartForm(multipartMemory); err != nil {
+		return c.checkBodyTooLarge(err)
+	}
+	form := c.Request.MultipartForm
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errBindTarget
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	fileFields := make(map[string]bool)
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		key := field.Tag.Get("form")
+		if key == "" || key == "-" || !field.IsExported() {
+			continue
+		}
+
+		switch field.Type {
+		case fileHeaderType:
+			fileFields[key] = true
+			if files := form.File[key]; len(files) > 0 {
+				rv.Field(i).Set(reflect.ValueOf(files[0]))
+			}
+		case fileHeaderSliceType:
+			fileFields[key] = true
+			if files := form.File[key]; len(files) > 0 {
+				rv.Field(i).Set(reflect.ValueOf(files))
+			}
+		}
+	}
+
+	return bindSource(v, "form", func(key string) []string {
+		if fileFields[key] {
+			return nil
+		}
+		return form.Value[key]
+	})
+}
+
+func bindValues(v any, tag string, values url.Values) error {
+	return bindSource(v, tag, func(key string) []string { return values[key] })
+}
+
+// bindSource sets every field of v tagged with tag from the values returned by
+// lookup. Fields whose key is absent fall back to their `default` tag, if any.
+func bindSource(v any, tag string, lookup func(key string) []string) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errBindTarget
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		key := field.Tag.Get(tag)
+		if key == "" || key == "-" || !field.IsExported() {
+			continue
+		}
+
+		raw := lookup(key)
+		if len(raw) == 0 {
+			def, hasDefault := field.Tag.Lookup("default")
+			if !hasDefault {
+				continue
+			}
+			if err := setField(rv.Field(i), []string{def}); err != nil {
+				return fmt.Errorf("invalid default for field %q: %w", field.Name, err)
+			}
+			continue
+		}
+
+		if err := setField(rv.Field(i), raw); err != nil {
+			return fmt.Errorf("invalid value for %s %q: %w", tag, key, err)
+		}
+	}
+
+	return nil
+}
+
+func setField(field reflect.Value, raw []string) error {
+	if field.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(field.Type(), len(raw), len(raw))
+		for i, s := range raw {
+			if err := setValue(slice.Index(i), s); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+	return setValue(field, raw[0])
+}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+func setValue(field reflect.Value, s string) error {
+	// time.Time and user types implementing encoding.TextUnmarshaler
+	if field.CanAddr() && field.Addr().Type().Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.Pointer:
+		ptr := reflect.New(field.Type().Elem())
+		if err := setValue(ptr.Elem(), s); err != nil {
+			return err
+		}
+		field.Set(ptr)
+	case reflect.String:
+		field.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package hikari
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// userID implements encoding.TextUnmarshaler, accepting "u-" prefixed IDs.
+type userID string
+
+func (id *userID) UnmarshalText(text []byte) error {
+	if !strings.HasPrefix(string(text), "u-") {
+		return errors.New("user ID must start with u-")
+	}
+	*id = userID(text)
+	return nil
+}
+
+type searchQuery struct {
+	Since  time.Time     `query:"since"`
+	Within time.Duration `query:"within"`
+	Owner  userID        `query:"owner"`
+	Author *userID       `query:"author"`
+}
+
+func TestBindQueryTextUnmarshalers(t *testing.T) {
+	c := &Context{Request: httptest.NewRequest("GET", "/?since=2024-01-02T15:04:05Z&within=90m&owner=u-1&author=u-2", nil)}
+
+	var q searchQuery
+	if err := c.BindQuery(&q); err != nil {
+		t.Fatalf("BindQuery: %v", err)
+	}
+	if want := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC); !q.Since.Equal(want) {
+		t.Errorf("Since = %v, want %v", q.Since, want)
+	}
+	if q.Within != 90*time.Minute {
+		t.Errorf("Within = %v, want 90m", q.Within)
+	}
+	if q.Owner != "u-1" || q.Author == nil || *q.Author != "u-2" {
+		t.Errorf("Owner = %q, Author = %v", q.Owner, q.Author)
+	}
+}
+
+func TestBindQueryInvalidValues(t *testing.T) {
+	tests := []struct {
+		query string
+		key   string
+	}{
+		{"since=yesterday", "since"},
+		{"owner=42", "owner"},
+		{"within=soon", "within"},
+	}
+	for _, tt := range tests {
+		c := &Context{Request: httptest.NewRequest("GET", "/?"+tt.query, nil)}
+		var q searchQuery
+		err := c.BindQuery(&q)
+		if err == nil {
+			t.Errorf("%s: expected an error", tt.query)
+			continue
+		}
+		if !strings.Contains(err.Error(), `"`+tt.key+`"`) {
+			t.Errorf("%s: error %q doesn't name the key", tt.query, err)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package hikari
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Headers set by hosting platforms with the real client IP, for SetTrustedPlatform.
+const (
+	PlatformCloudflare      = "CF-Connecting-IP"
+	PlatformGoogleAppEngine = "X-Appengine-User-IP"
+	PlatformFlyIO           = "Fly-Client-IP"
+	PlatformTrueClientIP    = "True-Client-IP"
+)
+
+// SetTrustedProxies sets the proxies, as IPs or CIDRs, whose forwarding headers
+// are trusted by Context.ClientIP. No proxy is trusted by default.
+func (a *App) SetTrustedProxies(proxies ...string) error {
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return err
+		}
+		nets = append(nets, ipNet)
+	}
+	a.trustedProxies = nets
+	return nil
+}
+
+// SetTrustedPlatform makes Context.ClientIP read the client IP from the given
+// platform header, such as PlatformCloudflare, instead of X-Forwarded-For. The
+// header is only honored when the request comes from a trusted proxy.
+func (a *App) SetTrustedPlatform(header string) {
+	a.trustedPlatform = http.CanonicalHeaderKey(header)
+}
+
+func (a *App) isTrustedProxy(ip net.IP) bool {
+	for _, ipNet := range a.trustedProxies {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the IP of the client that made the request. Forwarding
+// headers are only read when the direct peer is a trusted proxy: the trusted
+// platform header first, then X-Forwarded-For (skipping trusted proxies from
+// the right) and X-Real-IP.
+func (c *Context) ClientIP() string {
+	remoteIP := c.remoteIP()
+
+	app := c.app
+	if !c.fromTrustedProxy() {
+		return remoteIP
+	}
+
+	if app.trustedPlatform != "" {
+		if ip := strings.TrimSpace(c.Request.Header.Get(app.trustedPlatform)); net.ParseIP(ip) != nil {
+			return ip
+		}
+	}
+
+	if forwarded := c.Request.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				break
+			}
+			if i == 0 || !app.isTrustedProxy(ip) {
+				return ip.String()
+			}
+		}
+	}
+
+	if ip := strings.TrimSpace(c.Request.Header.Get("X-Real-IP")); net.ParseIP(ip) != nil {
+		return ip
+	}
+
+	return remoteIP
+}
+
+// Scheme returns "https" or "http". Behind a trusted proxy the
+// X-Forwarded-Proto header is honored.
+func (c *Context) Scheme() string {
+	if c.fromTrustedProxy() {
+		if proto := strings.TrimSpace(strings.Split(c.Request.Header.Get("X-Forwarded-Proto"), ",")[0]); proto != "" {
+			return strings.ToLower(proto)
+		}
+	}
+	if c.IsTLS() {
+		return "https"
+	}
+	return "http"
+}
+
+func (c *Context) remoteIP() string {
+	remoteIP, _, err := net.SplitHostPort(c.Request.RemoteAddr)
+	if err != nil {
+		return c.Request.RemoteAddr
+	}
+	return remoteIP
+}
+
+// fromTrustedProxy reports whether the direct peer is a trusted proxy.
+func (c *Context) fromTrustedProxy() bool {
+	return c.app != nil && c.app.isTrustedProxy(net.ParseIP(c.remoteIP()))
+}
@@ -11,9 +11,27 @@ type WebSocketConfig struct {
 	HandshakeTimeout  time.Duration
 	CheckOrigin       func(r *http.Request) bool
 	EnableCompression bool
-	PingInterval      time.Duration
-	PongTimeout       time.Duration
-	RegisterTimeout   time.Duration
+	// Deflate level used when EnableCompression is set, from flate.HuffmanOnly (-2)
+	// to flate.BestCompression (9). 0 or an invalid level keeps the default (BestSpeed)
+	CompressionLevel int
+	PingInterval     time.Duration
+	PongTimeout      time.Duration
+	RegisterTimeout  time.Duration
+	// Deadline for each message or ping write
+	WriteWait time.Duration
+
+	// Maximum number of connections per hub, 0 is unlimited. Upgrades over the
+	// limit are rejected with 503 before the connection is hijacked
+	MaxConnections int
+
+	// Number of broadcast messages kept per hub and replayed to new connections, 0 disables it
+	HistorySize int
+
+	// Per-connection message rate limiting, disabled when MaxMessagesPerSecond is 0
+	MaxMessagesPerSecond float64
+	MessageBurst         int
+	// Close the connection after this many rate limit violations, 0 never closes
+	MaxRateViolations int
 }
 
 func DefaultWebSocketConfig() *WebSocketConfig {
@@ -26,5 +44,6 @@ func DefaultWebSocketConfig() *WebSocketConfig {
 		PingInterval:      30 * time.Second,
 		PongTimeout:       60 * time.Second,
 		RegisterTimeout:   30 * time.Second,
+		WriteWait:         10 * time.Second,
 	}
 }
@@ -0,0 +1,42 @@
+package hikari
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// RequireContentType rejects requests carrying a body whose Content-Type is not
+// one of types with 415 Unsupported Media Type. Requests without a body pass through.
+func RequireContentType(types ...string) Middleware {
+	allowed := make(map[string]bool, len(types))
+	for _, t := range types {
+		allowed[strings.ToLower(t)] = true
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			if !hasBody(c.Request) {
+				next(c)
+				return
+			}
+
+			mediaType, _, err := mime.ParseMediaType(c.Request.Header.Get("Content-Type"))
+			if err != nil || !allowed[mediaType] {
+				c.JSON(http.StatusUnsupportedMediaType, H{
+					"error":    "unsupported content type",
+					"expected": types,
+				})
+				return
+			}
+
+			next(c)
+		}
+	}
+}
+
+// hasBody reports whether the request may carry a body. A ContentLength of -1
+// means unknown (chunked or HTTP/2 without Content-Length).
+func hasBody(req *http.Request) bool {
+	return req.Body != nil && req.Body != http.NoBody && req.ContentLength != 0
+}
@@ -1,63 +1,495 @@
 package hikari
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"net/http"
+	"os"
+	"reflect"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
 )
 
+// ErrBodyTooLarge is returned by Bind and BodyBytes when the request body
+// exceeds the app's max body size. The 413 response has already been written.
+var ErrBodyTooLarge = errors.New("request body too large")
+
+// ErrEmptyBody is returned by Bind when the request has no body.
+var ErrEmptyBody = errors.New("request body is empty")
+
+// ErrTooManyItems is returned by BindSlice when the JSON array has more
+// elements than allowed.
+var ErrTooManyItems = errors.New("too many items in request body")
+
 type Context struct {
 	context.Context
-	Writer  *responseWriter
+	Writer  ResponseWriter
 	Request *http.Request
 	Params  map[string]string
 	Logger  *zap.Logger
 
 	storage      map[string]interface{}
 	mutexStorage sync.RWMutex
+
+	aborted bool
+	body    []byte
+
+	// Pattern of the matched route and the name of its catch-all parameter
+	route       string
+	routeMeta   map[string]any
+	wildcardKey string
+
+	// App serving the request, nil for contexts created outside of it
+	app *App
+
+	// Names of Named middlewares skipped by the route matching skipsMethod
+	skipMiddlewares map[string]bool
+	skipsMethod     string
+	// Names of the Named middlewares that ran, when App.SetMiddlewareTrace is on
+	middlewareTrace []string
+	// Timings of the Named middlewares, when App.EnableMiddlewareProfiling is on
+	middlewareTimings []MiddlewareTiming
+	profileStack      []int
+
+	// Writes the response when the body exceeds the max body size
+	bodyTooLarge HandlerFunc
+	// Writes error responses for Error, defaults to a JSON body
+	errorHandler ErrorHandler
+}
+
+// ErrorHandler writes the response for an error raised by the framework or by
+// handlers through Context.Error.
+type ErrorHandler func(c *Context, status int, err error)
+
+func defaultErrorHandler(c *Context, status int, err error) {
+	c.JSONError(status, err.Error())
 }
 
+// ErrorEnvelope selects the shape of the JSON body written by JSONError.
+type ErrorEnvelope int
+
+const (
+	// ErrorEnvelopeFlat writes {"error": "message"}
+	ErrorEnvelopeFlat ErrorEnvelope = iota
+	// ErrorEnvelopeNested writes {"error": {"message": "message", "code": status}}
+	ErrorEnvelopeNested
+)
+
+// JSON writes v as a JSON response. The Content-Type defaults to
+// application/json but a type set beforehand, such as application/problem+json,
+// is kept. Nothing is written if the client already went away, and an encoding
+// error caused by a disconnect is only logged at debug level.
 func (c *Context) JSON(status int, v any) {
-	c.Writer.Header().Set("Content-Type", "application/json")
+	if c.responded() {
+		return
+	}
+	if err := c.Request.Context().Err(); err != nil {
+		c.logClientGone(err)
+		return
+	}
+	if c.Writer.Header().Get("Content-Type") == "" {
+		c.Writer.Header().Set("Content-Type", "application/json")
+	}
 	c.Writer.WriteHeader(status)
-	_ = json.NewEncoder(c.Writer).Encode(v)
+	if err := json.NewEncoder(c.Writer).Encode(v); err != nil && c.Request.Context().Err() != nil {
+		c.logClientGone(c.Request.Context().Err())
+	}
+}
+
+// OK writes v as a 200 JSON response.
+func (c *Context) OK(v any) {
+	c.JSON(http.StatusOK, v)
+}
+
+// Created writes v as a 201 JSON response, with the Location header set to
+// location when it isn't empty.
+func (c *Context) Created(location string, v any) {
+	if location != "" && !c.responded() {
+		c.SetHeader("Location", location)
+	}
+	c.JSON(http.StatusCreated, v)
+}
+
+// Accepted writes v as a 202 JSON response.
+func (c *Context) Accepted(v any) {
+	c.JSON(http.StatusAccepted, v)
+}
+
+// NoContent writes a 204 response without a body.
+func (c *Context) NoContent() {
+	if c.responded() {
+		return
+	}
+	c.Writer.WriteHeader(http.StatusNoContent)
+}
+
+// JSONError writes a JSON error response using the app's error envelope.
+func (c *Context) JSONError(status int, message string) {
+	if c.app != nil && c.app.errorEnvelope == ErrorEnvelopeNested {
+		c.JSON(status, H{"error": H{"message": message, "code": status}})
+		return
+	}
+	c.JSON(status, H{"error": message})
+}
+
+func (c *Context) JSONErrorf(status int, format string, values ...any) {
+	c.JSONError(status, fmt.Sprintf(format, values...))
 }
 
+// String writes a formatted response. The Content-Type defaults to text/plain
+// but a type set beforehand, such as text/html or text/csv, is kept.
 func (c *Context) String(status int, format string, values ...any) {
-	c.Writer.Header().Set("Content-Type", "text/plain")
+	if c.responded() {
+		return
+	}
+	if c.Writer.Header().Get("Content-Type") == "" {
+		c.Writer.Header().Set("Content-Type", "text/plain")
+	}
 	c.Writer.WriteHeader(status)
 	_, _ = c.Writer.Write([]byte(fmt.Sprintf(format, values...)))
 }
 
+// Text writes s as a text/plain response, whatever Content-Type was set before.
+// Unlike String, s is written as is, not used as a format.
+func (c *Context) Text(status int, s string) {
+	if c.responded() {
+		return
+	}
+	c.Writer.Header().Set("Content-Type", "text/plain")
+	c.Writer.WriteHeader(status)
+	_, _ = c.Writer.Write([]byte(s))
+}
+
+// Abort prevents the remaining middlewares and the handler from being called.
+// It does not stop the currently running middleware.
+func (c *Context) Abort() {
+	c.aborted = true
+}
+
+func (c *Context) IsAborted() bool {
+	return c.aborted
+}
+
+// MiddlewareTimings returns the time spent in each Named middleware that ran,
+// excluding the rest of the chain, in the order they started. It is empty unless
+// App.EnableMiddlewareProfiling is on.
+func (c *Context) MiddlewareTimings() []MiddlewareTiming {
+	return c.middlewareTimings
+}
+
+// MiddlewareTrace returns the names of the Named middlewares that ran so far,
+// in order, for debugging middleware ordering. Middlewares without a name and
+// skipped ones aren't listed. It is empty unless App.SetMiddlewareTrace is on.
+func (c *Context) MiddlewareTrace() []string {
+	return c.middlewareTrace
+}
+
+// Route returns the pattern of the matched route, or an empty string when no
+// route has been matched yet.
+func (c *Context) Route() string {
+	return c.route
+}
+
+// RouteMeta returns a metadata value attached to the matched route with Route.Set.
+func (c *Context) RouteMeta(key string) (any, bool) {
+	value, ok := c.routeMeta[key]
+	return value, ok
+}
+
+// LogWith adds fields to the request logger, so they are included in every
+// later log line of the request, including the logger middleware's completion log.
+func (c *Context) LogWith(fields ...zap.Field) {
+	c.Logger = c.Logger.With(fields...)
+}
+
 func (c *Context) Param(key string) string {
 	return c.Params[key]
 }
 
+// Wildcard returns the path captured by the route's catch-all segment,
+// whether it is unnamed (*) or named (*name).
 func (c *Context) Wildcard() string {
-	return c.Params["*"]
+	if c.wildcardKey == "" {
+		return c.Params["*"]
+	}
+	return c.Params[c.wildcardKey]
 }
 
 func (c *Context) Query(key string) string {
 	return c.Request.URL.Query().Get(key)
 }
 
+// QueryArray returns every value of a repeated query parameter, such as
+// ?tag=a&tag=b.
+func (c *Context) QueryArray(key string) []string {
+	return c.Request.URL.Query()[key]
+}
+
+// QueryMap returns the query parameters written as prefix[key]=value, such as
+// ?filter[status]=open, keyed by the bracketed name. Only the first value of a
+// repeated key is kept.
+func (c *Context) QueryMap(prefix string) map[string]string {
+	result := make(map[string]string)
+	for name, values := range c.Request.URL.Query() {
+		key, ok := strings.CutPrefix(name, prefix+"[")
+		if !ok || len(values) == 0 {
+			continue
+		}
+		if key, ok = strings.CutSuffix(key, "]"); ok && key != "" {
+			result[key] = values[0]
+		}
+	}
+	return result
+}
+
 func (c *Context) FormValue(key string) string {
 	return c.Request.FormValue(key)
 }
 
 func (c *Context) Bind(v any) error {
-	return json.NewDecoder(c.Request.Body).Decode(v)
+	if err := c.checkJSONLimits(); err != nil {
+		return err
+	}
+
+	if c.body != nil {
+		if len(bytes.TrimSpace(c.body)) == 0 {
+			return ErrEmptyBody
+		}
+		return json.Unmarshal(c.body, v)
+	}
+	if !hasBody(c.Request) {
+		return ErrEmptyBody
+	}
+
+	err := json.NewDecoder(c.Request.Body).Decode(v)
+	if errors.Is(err, io.EOF) {
+		return ErrEmptyBody
+	}
+	return c.checkBodyTooLarge(err)
+}
+
+// BindOptional works like Bind but treats an empty body as a no-op, leaving v
+// untouched, for endpoints where the body is optional.
+func (c *Context) BindOptional(v any) error {
+	if err := c.Bind(v); !errors.Is(err, ErrEmptyBody) {
+		return err
+	}
+	return nil
+}
+
+// BindSlice binds a top-level JSON array into the slice pointed to by v,
+// returning ErrTooManyItems as soon as it holds more than maxItems elements, or
+// without a limit when maxItems <= 0. Elements are decoded one at a time so
+// oversized arrays are not fully read, unless the app's JSON depth and element
+// limits require scanning the body first like Bind. An empty body returns
+// ErrEmptyBody, and data after the array is rejected.
+func (c *Context) BindSlice(v any, maxItems int) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return errors.New("bind target must be a non-nil pointer to a slice")
+	}
+	slice := rv.Elem()
+	elemType := slice.Type().Elem()
+
+	if err := c.checkJSONLimits(); err != nil {
+		return err
+	}
+
+	var body io.Reader = c.Request.Body
+	if c.body != nil {
+		body = bytes.NewReader(c.body)
+	}
+	dec := json.NewDecoder(body)
+
+	if tok, err := dec.Token(); err != nil {
+		if errors.Is(err, io.EOF) {
+			return ErrEmptyBody
+		}
+		return c.checkBodyTooLarge(err)
+	} else if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return errors.New("request body is not a JSON array")
+	}
+
+	items := reflect.MakeSlice(slice.Type(), 0, 0)
+	for dec.More() {
+		if maxItems > 0 && items.Len() >= maxItems {
+			return fmt.Errorf("%w: maximum is %d", ErrTooManyItems, maxItems)
+		}
+		elem := reflect.New(elemType)
+		if err := dec.Decode(elem.Interface()); err != nil {
+			return c.checkBodyTooLarge(err)
+		}
+		items = reflect.Append(items, elem.Elem())
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return c.checkBodyTooLarge(err)
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		if err != nil {
+			return c.checkBodyTooLarge(err)
+		}
+		return errors.New("unexpected data after the JSON array")
+	}
+
+	slice.Set(items)
+	return nil
+}
+
+// BindStream decodes a top-level JSON array one element at a time, so huge
+// arrays can be processed without holding them in memory. fn receives a decode
+// function filling v with the next element; it returns io.EOF after the last
+// one and the context error once the request deadline expires or the client
+// goes away. The app's max body size still applies:
+//
+//	err := c.BindStream(func(decode func(v any) error) error {
+//		for {
+//			var item Item
+//			if err := decode(&item); err == io.EOF {
+//				return nil
+//			} else if err != nil {
+//				return err
+//			}
+//			save(item)
+//		}
+//	})
+func (c *Context) BindStream(fn func(decode func(v any) error) error) error {
+	var body io.Reader = c.Request.Body
+	if c.body != nil {
+		body = bytes.NewReader(c.body)
+	}
+	dec := json.NewDecoder(body)
+
+	if tok, err := dec.Token(); err != nil {
+		if errors.Is(err, io.EOF) {
+			return ErrEmptyBody
+		}
+		return c.checkBodyTooLarge(err)
+	} else if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return errors.New("request body is not a JSON array")
+	}
+
+	done := false
+	decode := func(v any) error {
+		if done {
+			return io.EOF
+		}
+		if err := c.Err(); err != nil {
+			return err
+		}
+		if !dec.More() {
+			done = true
+			if _, err := dec.Token(); err != nil {
+				return c.checkBodyTooLarge(err)
+			}
+			return io.EOF
+		}
+		return c.checkBodyTooLarge(dec.Decode(v))
+	}
+	return fn(decode)
+}
+
+// MustBind binds the JSON body into v. On failure it writes a 400 through the
+// error handler, aborts the chain and returns false:
+//
+//	if !c.MustBind(&req) {
+//		return
+//	}
+func (c *Context) MustBind(v any) bool {
+	err := c.Bind(v)
+	if err == nil {
+		return true
+	}
+	if !errors.Is(err, ErrBodyTooLarge) {
+		c.Error(http.StatusBadRequest, err)
+		c.Abort()
+	}
+	return false
+}
+
+// Error writes an error response with the app's error handler.
+func (c *Context) Error(status int, err error) {
+	if c.errorHandler != nil {
+		c.errorHandler(c, status, err)
+		return
+	}
+	defaultErrorHandler(c, status, err)
+}
+
+// BodyBytes reads the whole request body, subject to the app's max body size,
+// and caches it so it can be read again by later calls and by Bind.
+func (c *Context) BodyBytes() ([]byte, error) {
+	if c.body != nil {
+		return c.body, nil
+	}
+	if c.Request.Body == nil {
+		return []byte{}, nil
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, c.checkBodyTooLarge(err)
+	}
+	c.Request.Body.Close()
+
+	c.body = body
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func (c *Context) BodyString() (string, error) {
+	body, err := c.BodyBytes()
+	return string(body), err
+}
+
+// checkBodyTooLarge writes the 413 response and aborts the chain when err comes
+// from exceeding the max body size, returning ErrBodyTooLarge in that case.
+func (c *Context) checkBodyTooLarge(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if !errors.As(err, &maxBytesErr) {
+		return err
+	}
+
+	if c.bodyTooLarge != nil {
+		c.bodyTooLarge(c)
+	} else {
+		c.Error(http.StatusRequestEntityTooLarge, ErrBodyTooLarge)
+	}
+	c.Abort()
+	return ErrBodyTooLarge
+}
+
+// responded reports whether the chain was aborted after a response was written,
+// in which case later writes are dropped to avoid double responses.
+func (c *Context) responded() bool {
+	return c.aborted && c.Writer.Written()
 }
 
 func (c *Context) SetHeader(key, value string) {
 	c.Writer.Header().Set(key, value)
 }
 
+// SetTrailer sets an HTTP trailer, sent after the response body. It can be
+// called before or after writing the body; when called before, the trailer is
+// also announced in the Trailer header. Trailers require HTTP/2 or a chunked
+// HTTP/1.1 response, so they are dropped when Content-Length is set.
+func (c *Context) SetTrailer(key, value string) {
+	key = http.CanonicalHeaderKey(key)
+	if !c.Written() {
+		c.Writer.Header().Add("Trailer", key)
+	}
+	c.Writer.Header().Set(http.TrailerPrefix+key, value)
+}
+
 func (c *Context) GetHeader(key string) string {
 	return c.Writer.Header().Get(key)
 }
@@ -70,6 +502,36 @@ func (c *Context) Path() string {
 	return c.Request.URL.Path
 }
 
+func (c *Context) Referer() string {
+	return c.Request.Referer()
+}
+
+func (c *Context) UserAgent() string {
+	return c.Request.UserAgent()
+}
+
+// IsTLS reports whether the connection to the server uses TLS. Use Scheme to
+// account for TLS terminated at a trusted proxy.
+func (c *Context) IsTLS() bool {
+	return c.Request.TLS != nil
+}
+
+// FullPath returns the request path including the raw query string, if any.
+func (c *Context) FullPath() string {
+	return c.Request.URL.RequestURI()
+}
+
+// IsWebSocket reports whether the request asks for a WebSocket upgrade.
+func (c *Context) IsWebSocket() bool {
+	return isWebSocketRequest(c.Request)
+}
+
+// Header values are matched as case-insensitive tokens, browsers may send
+// "Connection: keep-alive, Upgrade".
+func isWebSocketRequest(req *http.Request) bool {
+	return websocket.IsWebSocketUpgrade(req)
+}
+
 func (c *Context) Status(status int) {
 	c.Writer.WriteHeader(status)
 }
@@ -78,10 +540,77 @@ func (c *Context) GetStatus() int {
 	return c.Writer.StatusCode()
 }
 
+// OnResponse registers fn to run right before the response headers are sent,
+// so middleware can set headers based on values computed by the handler.
+// Hooks run once, in registration order, after the status is known (GetStatus
+// returns the final status) and before any byte of the body is written. They
+// should only modify headers: the response is already committed, so writing
+// from a hook is not supported. Hooks registered after the headers were sent
+// never run.
+func (c *Context) OnResponse(fn func()) {
+	if rw, ok := baseResponseWriter(c.Writer); ok {
+		rw.beforeWrite = append(rw.beforeWrite, fn)
+	}
+}
+
+// Written reports whether the response has already been committed. The status
+// can only be set once, so later calls to Status, JSON or String won't change it.
+func (c *Context) Written() bool {
+	return c.Writer.Written()
+}
+
+// File serves the file at filePath. A missing file gets the app's not found
+// response, from the NotFound handler or in the default response format.
 func (c *Context) File(filePath string) {
+	if _, err := os.Stat(filePath); errors.Is(err, fs.ErrNotExist) {
+		c.notFound()
+		return
+	}
 	http.ServeFile(c.Writer, c.Request, filePath)
 }
 
+// FileFS serves the file name from fsys, such as an embed.FS. The content type,
+// conditional requests and missing files are handled like File.
+func (c *Context) FileFS(fsys fs.FS, name string) {
+	if _, err := fs.Stat(fsys, name); errors.Is(err, fs.ErrNotExist) {
+		c.notFound()
+		return
+	}
+	http.ServeFileFS(c.Writer, c.Request, fsys, name)
+}
+
+// notFound writes the app's not found response.
+func (c *Context) notFound() {
+	if c.app == nil {
+		http.NotFound(c.Writer, c.Request)
+		return
+	}
+	c.app.router.serveNotFound(c)
+}
+
+// FileWithCache serves a file like File, with Cache-Control set to max-age and
+// a weak ETag derived from the file size and modification time. Conditional
+// requests (If-Modified-Since, If-None-Match) get a 304 when the file hasn't
+// changed.
+func (c *Context) FileWithCache(filePath string, maxAge time.Duration) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		c.notFound()
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		c.notFound()
+		return
+	}
+
+	c.SetHeader("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+	c.SetHeader("ETag", fmt.Sprintf(`W/"%x-%x"`, info.Size(), info.ModTime().UnixNano()))
+	http.ServeContent(c.Writer, c.Request, info.Name(), info.ModTime(), f)
+}
+
 func (c *Context) Set(key string, value interface{}) {
 	c.mutexStorage.Lock()
 	defer c.mutexStorage.Unlock()
@@ -136,6 +665,42 @@ func (c *Context) GetBool(key string) bool {
 	return false
 }
 
+func (c *Context) GetInt64(key string) int64 {
+	if value, exists := c.Get(key); exists {
+		if i, ok := value.(int64); ok {
+			return i
+		}
+	}
+	return 0
+}
+
+func (c *Context) GetFloat64(key string) float64 {
+	if value, exists := c.Get(key); exists {
+		if f, ok := value.(float64); ok {
+			return f
+		}
+	}
+	return 0
+}
+
+func (c *Context) GetDuration(key string) time.Duration {
+	if value, exists := c.Get(key); exists {
+		if d, ok := value.(time.Duration); ok {
+			return d
+		}
+	}
+	return 0
+}
+
+func (c *Context) GetTime(key string) time.Time {
+	if value, exists := c.Get(key); exists {
+		if t, ok := value.(time.Time); ok {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
 func (c *Context) Keys() []string {
 	c.mutexStorage.RLock()
 	defer c.mutexStorage.RUnlock()
@@ -151,6 +716,30 @@ func (c *Context) Keys() []string {
 	return keys
 }
 
+// Wait sleeps for d, returning early with the context error if the request is
+// cancelled or its timeout expires first. Use it between retries of outbound
+// calls so handlers don't block past the request timeout:
+//
+//	for attempt := 0; attempt < 3; attempt++ {
+//		if err = callService(c); err == nil {
+//			break
+//		}
+//		if err := c.Wait(time.Second); err != nil {
+//			return
+//		}
+//	}
+func (c *Context) Wait(d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-c.Context.Done():
+		return c.Context.Err()
+	}
+}
+
 func (c *Context) WithTimeout(timeout time.Duration) (context.Context, context.CancelFunc) {
 	return context.WithTimeout(c.Context, timeout)
 }
@@ -0,0 +1,276 @@
+package hikari
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBindBodyTooLarge(t *testing.T) {
+	app := newTestApp()
+	app.SetMaxBodySize(16)
+
+	var bindErr error
+	app.POST("/items", func(c *Context) {
+		var v map[string]any
+		if bindErr = c.Bind(&v); bindErr != nil {
+			c.JSON(http.StatusBadRequest, H{"error": "invalid JSON"})
+			return
+		}
+		c.JSON(http.StatusOK, v)
+	})
+
+	body := `{"name": "` + strings.Repeat("x", 64) + `"}`
+	w := serve(app, httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(body)))
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want 413", w.Code)
+	}
+	if !errors.Is(bindErr, ErrBodyTooLarge) {
+		t.Fatalf("Bind error = %v, want ErrBodyTooLarge", bindErr)
+	}
+	if !strings.Contains(w.Body.String(), "request body too large") {
+		t.Fatalf("body = %q", w.Body.String())
+	}
+}
+
+func TestBodyTooLargeHandler(t *testing.T) {
+	app := newTestApp()
+	app.SetMaxBodySize(4)
+	app.SetBodyTooLargeHandler(func(c *Context) {
+		c.String(http.StatusRequestEntityTooLarge, "too big")
+	})
+	app.POST("/upload", func(c *Context) {
+		if _, err := c.BodyBytes(); err != nil {
+			return
+		}
+		c.String(http.StatusOK, "ok")
+	})
+
+	w := serve(app, httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("0123456789")))
+	if w.Code != http.StatusRequestEntityTooLarge || w.Body.String() != "too big" {
+		t.Fatalf("got %d %q, want 413 from the custom handler", w.Code, w.Body.String())
+	}
+}
+
+type patchTodo struct {
+	Title string `json:"title"`
+	Done  bool   `json:"done"`
+}
+
+func TestBindEmptyBody(t *testing.T) {
+	requests := map[string]*http.Request{
+		"no body":          httptest.NewRequest(http.MethodPost, "/", nil),
+		"content length 0": httptest.NewRequest(http.MethodPost, "/", strings.NewReader("")),
+		"whitespace":       httptest.NewRequest(http.MethodPost, "/", strings.NewReader("  \n")),
+	}
+	chunked := httptest.NewRequest(http.MethodPost, "/", io.NopCloser(strings.NewReader("")))
+	chunked.ContentLength = -1
+	requests["unknown length"] = chunked
+
+	for name, req := range requests {
+		c := &Context{Request: req}
+		var v patchTodo
+		if err := c.Bind(&v); !errors.Is(err, ErrEmptyBody) {
+			t.Errorf("%s: Bind error = %v, want ErrEmptyBody", name, err)
+		}
+	}
+}
+
+func TestBindOptional(t *testing.T) {
+	c := &Context{Request: httptest.NewRequest(http.MethodPatch, "/", nil)}
+	v := patchTodo{Title: "default"}
+	if err := c.BindOptional(&v); err != nil {
+		t.Fatalf("empty body: %v", err)
+	}
+	if v.Title != "default" {
+		t.Fatalf("empty body changed the value: %+v", v)
+	}
+
+	c = &Context{Request: httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"done": true}`))}
+	if err := c.BindOptional(&v); err != nil {
+		t.Fatalf("present body: %v", err)
+	}
+	if !v.Done || v.Title != "default" {
+		t.Fatalf("present body: got %+v", v)
+	}
+
+	c = &Context{Request: httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"done": `))}
+	if err := c.BindOptional(&v); err == nil || errors.Is(err, ErrEmptyBody) {
+		t.Fatalf("invalid body: got %v, want a syntax error", err)
+	}
+}
+
+func TestSetTrailer(t *testing.T) {
+	app := newTestApp()
+	app.GET("/data", func(c *Context) {
+		c.SetTrailer("X-Checksum", "")
+		body := []byte("some streamed data")
+		c.Status(http.StatusOK)
+		c.Writer.Write(body)
+		sum := sha256.Sum256(body)
+		c.SetTrailer("X-Checksum", hex.EncodeToString(sum[:]))
+		// Trailers can also be set only after the body, without being announced
+		c.SetTrailer("X-Rows", "1")
+	})
+
+	srv := httptest.NewServer(app.buildHandler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	sum := sha256.Sum256(body)
+	if got := resp.Trailer.Get("X-Checksum"); got != hex.EncodeToString(sum[:]) {
+		t.Errorf("X-Checksum trailer = %q", got)
+	}
+	if got := resp.Trailer.Get("X-Rows"); got != "1" {
+		t.Errorf("X-Rows trailer = %q", got)
+	}
+}
+
+func TestQueryArray(t *testing.T) {
+	c := &Context{Request: httptest.NewRequest("GET", "/items?tag=a&tag=b&page=2", nil)}
+
+	if got := c.QueryArray("tag"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Fatalf("QueryArray(tag) = %v", got)
+	}
+	if got := c.QueryArray("page"); !reflect.DeepEqual(got, []string{"2"}) {
+		t.Fatalf("QueryArray(page) = %v", got)
+	}
+	if got := c.QueryArray("missing"); got != nil {
+		t.Fatalf("QueryArray(missing) = %v, want nil", got)
+	}
+}
+
+func TestQueryMap(t *testing.T) {
+	c := &Context{Request: httptest.NewRequest("GET", "/items?filter[status]=open&filter[owner]=bob&filter[]=x&filter=y&sort[by]=date", nil)}
+
+	want := map[string]string{"status": "open", "owner": "bob"}
+	if got := c.QueryMap("filter"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("QueryMap(filter) = %v, want %v", got, want)
+	}
+	if got := c.QueryMap("missing"); len(got) != 0 {
+		t.Fatalf("QueryMap(missing) = %v, want empty", got)
+	}
+}
+
+func TestFileMissingUsesNotFoundResponse(t *testing.T) {
+	app := newTestApp()
+	app.SetDefaultResponseFormat(ResponseFormatJSON)
+	missing := filepath.Join(t.TempDir(), "missing.txt")
+	app.GET("/download", func(c *Context) {
+		c.File(missing)
+	})
+
+	w := serve(app, httptest.NewRequest("GET", "/download", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", w.Code)
+	}
+	var body map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body is not JSON: %q", w.Body.String())
+	}
+	if body["error"] != "not found" || body["path"] != "/download" {
+		t.Fatalf("body = %v", body)
+	}
+}
+
+func TestStringKeepsPresetContentType(t *testing.T) {
+	app := newTestApp()
+	app.GET("/snippet", func(c *Context) {
+		c.SetHeader("Content-Type", "text/html; charset=utf-8")
+		c.String(200, "<b>%s</b>", "hi")
+	})
+	app.GET("/text", func(c *Context) {
+		c.SetHeader("Content-Type", "text/html; charset=utf-8")
+		c.Text(200, "<b>hi</b>")
+	})
+
+	w := serve(app, httptest.NewRequest("GET", "/snippet", nil))
+	if got := w.Header().Get("Content-Type"); got != "text/html; charset=utf-8" {
+		t.Fatalf("String Content-Type = %q", got)
+	}
+	if w.Body.String() != "<b>hi</b>" {
+		t.Fatalf("String body = %q", w.Body.String())
+	}
+
+	w = serve(app, httptest.NewRequest("GET", "/text", nil))
+	if got := w.Header().Get("Content-Type"); got != "text/plain" {
+		t.Fatalf("Text Content-Type = %q, want text/plain", got)
+	}
+}
+
+func TestJSONAndStringKeepPresetContentType(t *testing.T) {
+	app := newTestApp()
+	app.GET("/json", func(c *Context) {
+		c.SetHeader("Content-Type", "application/problem+json")
+		c.JSON(400, H{"title": "bad request"})
+	})
+	app.GET("/string", func(c *Context) {
+		c.SetHeader("Content-Type", "application/problem+json")
+		c.String(400, `{"title":"bad request"}`)
+	})
+	app.GET("/default", func(c *Context) {
+		c.JSON(200, H{"ok": true})
+	})
+
+	for path, want := range map[string]string{
+		"/json":    "application/problem+json",
+		"/string":  "application/problem+json",
+		"/default": "application/json",
+	} {
+		w := serve(app, httptest.NewRequest("GET", path, nil))
+		if got := w.Header().Get("Content-Type"); got != want {
+			t.Errorf("%s: Content-Type = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestBindSlice(t *testing.T) {
+	type item struct {
+		A int `json:"a"`
+	}
+	bindSlice := func(body string, maxItems int) ([]item, error) {
+		c := &Context{Request: httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)), app: newTestApp()}
+		var items []item
+		err := c.BindSlice(&items, maxItems)
+		return items, err
+	}
+
+	if items, err := bindSlice(`[{"a":1},{"a":2}]`, 2); err != nil || len(items) != 2 || items[1].A != 2 {
+		t.Fatalf("BindSlice = %v, %v", items, err)
+	}
+	if _, err := bindSlice(`[{"a":1},{"a":2},{"a":3}]`, 2); !errors.Is(err, ErrTooManyItems) {
+		t.Fatalf("too many items: err = %v, want ErrTooManyItems", err)
+	}
+	if items, err := bindSlice(`[{"a":1},{"a":2},{"a":3}]`, 0); err != nil || len(items) != 3 {
+		t.Fatalf("no limit: BindSlice = %v, %v", items, err)
+	}
+	if _, err := bindSlice("", 10); !errors.Is(err, ErrEmptyBody) {
+		t.Fatalf("empty body: err = %v, want ErrEmptyBody", err)
+	}
+	if _, err := bindSlice(`[{"a":1}] garbage`, 10); err == nil {
+		t.Fatal("trailing data accepted")
+	}
+	if _, err := bindSlice(`[{"a":1}] [{"a":2}]`, 10); err == nil {
+		t.Fatal("second array accepted")
+	}
+
+	deep := "[" + strings.Repeat(`{"a":`, defaultMaxJSONDepth) + "1" + strings.Repeat("}", defaultMaxJSONDepth) + "]"
+	if _, err := bindSlice(deep, 10); !errors.Is(err, ErrJSONTooComplex) {
+		t.Fatalf("deep body: err = %v, want ErrJSONTooComplex", err)
+	}
+}
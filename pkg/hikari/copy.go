@@ -0,0 +1,77 @@
+package hikari
+
+import (
+	"bufio"
+	"context"
+	"maps"
+	"net"
+	"net/http"
+)
+
+// Copy returns a copy of c that can be used by a goroutine outliving the
+// request, e.g. for async logging. Its context is detached: it keeps the
+// request's values but is never cancelled and has no deadline. Params, route
+// metadata and storage are snapshots, and the request is a clone without a
+// body; the cached body read by BodyBytes or Bind is kept. The response is gone
+// once the handler returns, so writing through the copy panics.
+func (c *Context) Copy() *Context {
+	ctx := context.WithoutCancel(c.Context)
+
+	req := c.Request.Clone(ctx)
+	req.Body = http.NoBody
+
+	c.mutexStorage.RLock()
+	storage := maps.Clone(c.storage)
+	c.mutexStorage.RUnlock()
+	if storage == nil {
+		storage = make(map[string]interface{})
+	}
+
+	return &Context{
+		Context: ctx,
+		Writer:  &copiedWriter{header: c.Writer.Header().Clone(), status: c.Writer.StatusCode()},
+		Request: req,
+		Params:  maps.Clone(c.Params),
+		Logger:  c.Logger,
+
+		storage: storage,
+		aborted: c.aborted,
+		body:    c.body,
+
+		route:       c.route,
+		routeMeta:   maps.Clone(c.routeMeta),
+		wildcardKey: c.wildcardKey,
+
+		app:          c.app,
+		bodyTooLarge: c.bodyTooLarge,
+		errorHandler: c.errorHandler,
+	}
+}
+
+// copiedWriter is the Writer of a copied Context. Headers and the status are
+// a snapshot of the original response, anything writing to the client panics.
+type copiedWriter struct {
+	header http.Header
+	status int
+}
+
+const errCopiedWrite = "hikari: cannot write the response from a copied Context"
+
+func (w *copiedWriter) Header() http.Header { return w.header }
+
+func (w *copiedWriter) Write([]byte) (int, error) { panic(errCopiedWrite) }
+
+func (w *copiedWriter) WriteHeader(int) { panic(errCopiedWrite) }
+
+func (w *copiedWriter) Flush() { panic(errCopiedWrite) }
+
+func (w *copiedWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) { panic(errCopiedWrite) }
+
+func (w *copiedWriter) StatusCode() int { return w.status }
+
+// Written is always false so that the write helpers, which skip writing once
+// an aborted chain has responded, reach the panic even on a copy of an
+// aborted Context.
+func (w *copiedWriter) Written() bool { return false }
+
+func (w *copiedWriter) Unwrap() http.ResponseWriter { return nil }
@@ -0,0 +1,69 @@
+package hikari
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCopyOutlivesRequest(t *testing.T) {
+	app := newTestApp()
+	copies := make(chan *Context, 1)
+	app.GET("/users/:id", func(c *Context) {
+		c.Set("user", "alice")
+		copies <- c.Copy()
+		c.Set("user", "changed after copy")
+		c.String(200, "ok")
+	})
+
+	reqCtx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest("GET", "/users/42", nil).WithContext(reqCtx)
+	serve(app, req)
+	cancel()
+
+	cp := <-copies
+	if cp.Err() != nil {
+		t.Fatalf("copy context cancelled with the request: %v", cp.Err())
+	}
+	if cp.Param("id") != "42" || cp.GetString("user") != "alice" {
+		t.Fatalf("copy has id %q, user %q", cp.Param("id"), cp.GetString("user"))
+	}
+	cp.Set("extra", 1)
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("writing through a copy did not panic")
+		}
+	}()
+	cp.String(200, "late")
+}
+
+func TestWritingThroughCopyPanics(t *testing.T) {
+	app := newTestApp()
+	copies := make(chan *Context, 1)
+	app.GET("/", func(c *Context) {
+		c.String(http.StatusUnauthorized, "denied")
+		c.Abort()
+		copies <- c.Copy()
+	})
+	serve(app, httptest.NewRequest(http.MethodGet, "/", nil))
+	cp := <-copies
+
+	writes := map[string]func(){
+		"JSON":   func() { cp.JSON(http.StatusOK, H{"late": true}) },
+		"String": func() { cp.String(http.StatusOK, "late") },
+		"Status": func() { cp.Status(http.StatusOK) },
+		"NDJSON": func() { cp.NDJSON(http.StatusOK) },
+	}
+	for name, write := range writes {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s on a copy of an aborted Context did not panic", name)
+				}
+			}()
+			write()
+		}()
+	}
+}
@@ -1,33 +1,47 @@
 package hikari
 
+import "time"
+
 type Group struct {
 	prefix      string
 	middlewares []Middleware
 	app         *App
 }
 
-func (g *Group) GET(pattern string, handler HandlerFunc, middlewares ...Middleware) {
-	g.handle("GET", pattern, handler, middlewares...)
+func (g *Group) GET(pattern string, handler HandlerFunc, middlewares ...Middleware) *Route {
+	return g.handle("GET", pattern, handler, middlewares...)
+}
+
+func (g *Group) POST(pattern string, handler HandlerFunc, middlewares ...Middleware) *Route {
+	return g.handle("POST", pattern, handler, middlewares...)
+}
+
+func (g *Group) PUT(pattern string, handler HandlerFunc, middlewares ...Middleware) *Route {
+	return g.handle("PUT", pattern, handler, middlewares...)
 }
 
-func (g *Group) POST(pattern string, handler HandlerFunc, middlewares ...Middleware) {
-	g.handle("POST", pattern, handler, middlewares...)
+func (g *Group) PATCH(pattern string, handler HandlerFunc, middlewares ...Middleware) *Route {
+	return g.handle("PATCH", pattern, handler, middlewares...)
 }
 
-func (g *Group) PUT(pattern string, handler HandlerFunc, middlewares ...Middleware) {
-	g.handle("PUT", pattern, handler, middlewares...)
+func (g *Group) DELETE(pattern string, handler HandlerFunc, middlewares ...Middleware) *Route {
+	return g.handle("DELETE", pattern, handler, middlewares...)
 }
 
-func (g *Group) PATCH(pattern string, handler HandlerFunc, middlewares ...Middleware) {
-	g.handle("PATCH", pattern, handler, middlewares...)
+// Use registers group middlewares, executed in the order given.
+func (g *Group) Use(middlewares ...Middleware) {
+	g.middlewares = append(g.middlewares, middlewares...)
 }
 
-func (g *Group) DELETE(pattern string, handler HandlerFunc, middlewares ...Middleware) {
-	g.handle("DELETE", pattern, handler, middlewares...)
+// UseFunc registers a group middleware written as a MiddlewareFunc.
+func (g *Group) UseFunc(fn MiddlewareFunc) {
+	g.Use(fn.Middleware())
 }
 
-func (g *Group) Use(middleware Middleware) {
-	g.middlewares = append(g.middlewares, middleware)
+// SetTimeout sets the request timeout of the routes registered afterwards in
+// this group, replacing the app's request timeout for them.
+func (g *Group) SetTimeout(d time.Duration) {
+	g.Use(Timeout(d))
 }
 
 func (g *Group) Group(prefix string, middlewares ...Middleware) *Group {
@@ -46,11 +60,23 @@ func (g *Group) Group(prefix string, middlewares ...Middleware) *Group {
 	return newGroup
 }
 
-func (g *Group) handle(method, pattern string, handler HandlerFunc, middlewares ...Middleware) {
+// WebSocket registers a WebSocket route under the group prefix with the group
+// middlewares. The hub name is used as is, without the prefix.
+func (g *Group) WebSocket(path, hubName string, handler WebSocketHandler, middlewares ...Middleware) *Route {
+	fullPattern := buildPattern(g.prefix, path, g.app.logger)
+	return g.app.WebSocket(fullPattern, hubName, handler, g.allMiddlewares(middlewares)...)
+}
+
+// allMiddlewares returns the group middlewares followed by the route ones
+func (g *Group) allMiddlewares(middlewares []Middleware) []Middleware {
 	allMiddlewares := make([]Middleware, 0, len(g.middlewares)+len(middlewares))
-	copy(allMiddlewares, g.middlewares)
-	copy(allMiddlewares[len(g.middlewares):], middlewares)
+	allMiddlewares = append(allMiddlewares, g.middlewares...)
+	return append(allMiddlewares, middlewares...)
+}
+
+func (g *Group) handle(method, pattern string, handler HandlerFunc, middlewares ...Middleware) *Route {
+	allMiddlewares := g.allMiddlewares(middlewares)
 
 	fullPattern := buildPattern(g.prefix, pattern, g.app.logger)
-	g.app.router.handleNormalized(method, fullPattern, handler, allMiddlewares...)
+	return g.app.router.handleNormalized(method, fullPattern, handler, allMiddlewares...)
 }
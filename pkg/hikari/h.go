@@ -1,3 +1,44 @@
 package hikari
 
+import "encoding/json"
+
+// H is a shortcut for building ad-hoc JSON objects, used throughout the
+// framework for responses and error bodies.
 type H map[string]any
+
+// ToH converts a struct (or any value encoding to a JSON object) into an H,
+// honoring json tags. It returns nil if v doesn't encode to a JSON object.
+func ToH(v any) H {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+
+	var h H
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil
+	}
+	return h
+}
+
+// Merge returns a new H with the fields of h and other. Fields in other take
+// precedence over fields with the same key in h.
+func (h H) Merge(other H) H {
+	merged := make(H, len(h)+len(other))
+	for k, v := range h {
+		merged[k] = v
+	}
+	for k, v := range other {
+		merged[k] = v
+	}
+	return merged
+}
+
+// Bind decodes h into the struct pointed to by v, honoring json tags.
+func (h H) Bind(v any) error {
+	data, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -2,3 +2,7 @@ package hikari
 
 type HandlerFunc func(*Context)
 type WebSocketHandler func(*WSContext)
+
+// WSMiddleware wraps the handler of each WebSocket message, like Middleware for
+// HTTP requests. Register it on a hub with WebSocketHub.Use.
+type WSMiddleware func(WebSocketHandler) WebSocketHandler
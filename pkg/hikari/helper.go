@@ -1,6 +1,7 @@
 package hikari
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 
@@ -10,8 +11,10 @@ import (
 var (
 	// Regex para remover barras duplas ou múltiplas
 	duplicateSlashRegex = regexp.MustCompile(`/+`)
-	// Regex para validar o formato do padrão (letras, números, :param, *, -)
-	validPatternRegex = regexp.MustCompile(`^[a-zA-Z0-9/:*_-]*$`)
+	// Regex para validar o formato do padrão (letras, números, :param, *, -, .)
+	validPatternRegex = regexp.MustCompile(`^[a-zA-Z0-9/:*_.-]*$`)
+	// Regex para validar nomes de parâmetros e wildcards nomeados
+	paramNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
 )
 
 func normalizedPattern(pattern string) string {
@@ -38,7 +41,7 @@ func isValidPattern(pattern string) bool {
 	}
 
 	parts := strings.Split(pattern, "/")
-	for _, part := range parts {
+	for i, part := range parts {
 		if part == "" {
 			continue
 		}
@@ -49,27 +52,42 @@ func isValidPattern(pattern string) bool {
 			}
 
 			paramName := part[1:]
-			if !regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`).MatchString(paramName) {
+			if !paramNameRegex.MatchString(paramName) {
 				return false
 			}
 		}
 
-		// Wildcard só pode ser "*" sozinho
-		if strings.Contains(part, "*") && part != "*" {
-			return false
+		// Wildcard deve ser "*" ou "*nome" e sempre o último segmento
+		if strings.Contains(part, "*") {
+			if !strings.HasPrefix(part, "*") || i != len(parts)-1 {
+				return false
+			}
+			if name := part[1:]; name != "" && !paramNameRegex.MatchString(name) {
+				return false
+			}
 		}
 	}
 
 	return true
 }
 
+// buildPattern joins and normalizes prefix and pattern. It panics on invalid
+// patterns so misconfigured routes fail at startup instead of never matching.
 func buildPattern(prefix, pattern string, logger *zap.Logger) string {
 	fullPattern := normalizedPattern(prefix + pattern)
 
 	if !isValidPattern(fullPattern) {
 		logger.Error("Invalid route pattern", zap.String("pattern", fullPattern))
-		return "" // ou lançar um erro, dependendo do design da aplicação
+		panic(fmt.Sprintf("hikari: invalid route pattern %q", fullPattern))
 	}
 
 	return fullPattern
 }
+
+// wildcardName returns the parameter name of a catch-all segment ("*" or "*name").
+func wildcardName(part string) string {
+	if part == "*" {
+		return "*"
+	}
+	return strings.TrimPrefix(part, "*")
+}
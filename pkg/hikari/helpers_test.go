@@ -0,0 +1,58 @@
+package hikari
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// newTestApp returns an app that doesn't log.
+func newTestApp() *App {
+	app := New(":0")
+	app.logger = zap.NewNop()
+	app.router.logger = app.logger
+	return app
+}
+
+// serve runs req through the app's full handler chain and records the response.
+func serve(app *App, req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	app.buildHandler().ServeHTTP(w, req)
+	return w
+}
+
+// startServer serves app on a test server and returns its ws:// base URL.
+func startServer(t *testing.T, app *App) string {
+	t.Helper()
+	srv := httptest.NewServer(app.buildHandler())
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+// dialWS opens a WebSocket connection, closed when the test ends.
+func dialWS(t *testing.T, url string) *websocket.Conn {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial %s: %v", url, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// waitFor polls cond until it holds, failing the test after 2 seconds.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,39 @@
+package hikari
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"net/http"
+)
+
+var errNoTemplates = errors.New("no templates set, call App.SetTemplates first")
+
+// SetTemplates sets the templates rendered by Context.HTML, e.g.
+// template.Must(template.ParseGlob("templates/*.html")).
+func (a *App) SetTemplates(t *template.Template) {
+	a.templates = t
+}
+
+// HTML renders the template name with data. The output is buffered, so a
+// template failing halfway doesn't send a half-rendered page: the error goes
+// to the error handler with a 500 instead, as nothing was written yet.
+func (c *Context) HTML(status int, name string, data any) {
+	if c.responded() {
+		return
+	}
+	if c.app == nil || c.app.templates == nil {
+		c.Error(http.StatusInternalServerError, errNoTemplates)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := c.app.templates.ExecuteTemplate(&buf, name, data); err != nil {
+		c.Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.Writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	c.Writer.WriteHeader(status)
+	_, _ = buf.WriteTo(c.Writer)
+}
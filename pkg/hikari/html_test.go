@@ -0,0 +1,54 @@
+package hikari
+
+import (
+	"html/template"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type pageData struct {
+	Title string
+}
+
+func TestHTMLRendersTemplate(t *testing.T) {
+	app := newTestApp()
+	app.SetTemplates(template.Must(template.New("page").Parse("<h1>{{.Title}}</h1>")))
+	app.GET("/", func(c *Context) {
+		c.HTML(200, "page", pageData{Title: "<Home>"})
+	})
+
+	w := serve(app, httptest.NewRequest("GET", "/", nil))
+	if w.Code != 200 || w.Body.String() != "<h1>&lt;Home&gt;</h1>" {
+		t.Fatalf("got %d %q", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Type"); got != "text/html; charset=utf-8" {
+		t.Fatalf("Content-Type = %q", got)
+	}
+}
+
+func TestHTMLTemplateErrorWritesNothing(t *testing.T) {
+	app := newTestApp()
+	// The heading renders before the missing field fails
+	app.SetTemplates(template.Must(template.New("page").Parse("<h1>{{.Title}}</h1><p>{{.Missing}}</p>")))
+
+	var handled error
+	app.SetErrorHandler(func(c *Context, status int, err error) {
+		handled = err
+		c.String(status, "render failed")
+	})
+	app.GET("/", func(c *Context) {
+		c.HTML(200, "page", pageData{Title: "Home"})
+	})
+
+	w := serve(app, httptest.NewRequest("GET", "/", nil))
+	if w.Code != 500 {
+		t.Fatalf("status = %d, want 500", w.Code)
+	}
+	if w.Body.String() != "render failed" {
+		t.Fatalf("body = %q, want only the error response", w.Body.String())
+	}
+	if handled == nil || !strings.Contains(handled.Error(), "Missing") {
+		t.Fatalf("error handler got %v", handled)
+	}
+}
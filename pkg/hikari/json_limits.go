@@ -0,0 +1,95 @@
+package hikari
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrJSONTooComplex is returned by Bind and BindSlice when the body exceeds the
+// maximum JSON nesting depth or number of elements per array/object.
+var ErrJSONTooComplex = errors.New("JSON body too complex")
+
+const (
+	defaultMaxJSONDepth    = 64
+	defaultMaxJSONElements = 100000
+)
+
+// SetMaxJSONDepth sets the maximum nesting depth accepted by Bind and
+// BindSlice, 0 disables the check. Defaults to 64.
+func (a *App) SetMaxJSONDepth(depth int) {
+	a.maxJSONDepth = depth
+}
+
+// SetMaxJSONElements sets the maximum number of elements of a single JSON
+// array or object accepted by Bind and BindSlice, 0 disables the check.
+// Defaults to 100000.
+func (a *App) SetMaxJSONElements(n int) {
+	a.maxJSONElements = n
+}
+
+// checkJSONLimits reads the body and checks it against the app's JSON depth
+// and element limits, if any.
+func (c *Context) checkJSONLimits() error {
+	if c.app == nil || (c.app.maxJSONDepth <= 0 && c.app.maxJSONElements <= 0) {
+		return nil
+	}
+	body, err := c.BodyBytes()
+	if err != nil {
+		return err
+	}
+	return checkJSONLimits(body, c.app.maxJSONDepth, c.app.maxJSONElements)
+}
+
+// checkJSONLimits scans data without decoding it into values and fails as soon
+// as the nesting depth or a container's element count goes past the limits.
+// Syntax errors are left for the actual decoding to report.
+func checkJSONLimits(data []byte, maxDepth, maxElements int) error {
+	type container struct {
+		object    bool
+		expectKey bool
+		elements  int
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var stack []container
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			// io.EOF once the whole body was scanned
+			return nil
+		}
+
+		delim, isDelim := tok.(json.Delim)
+		if isDelim && (delim == '}' || delim == ']') {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		if len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			if !top.object || top.expectKey {
+				top.elements++
+				if maxElements > 0 && top.elements > maxElements {
+					return fmt.Errorf("%w: more than %d elements", ErrJSONTooComplex, maxElements)
+				}
+			}
+			if top.object {
+				top.expectKey = !top.expectKey
+				if !top.expectKey {
+					// This token was a key, its value comes next
+					continue
+				}
+			}
+		}
+
+		if isDelim {
+			stack = append(stack, container{object: delim == '{', expectKey: true})
+			if maxDepth > 0 && len(stack) > maxDepth {
+				return fmt.Errorf("%w: nesting deeper than %d", ErrJSONTooComplex, maxDepth)
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package hikari
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func bindWithApp(app *App, body string, v any) error {
+	c := &Context{Request: httptest.NewRequest("POST", "/", strings.NewReader(body)), app: app}
+	return c.Bind(v)
+}
+
+func TestBindRejectsDeepNesting(t *testing.T) {
+	app := newTestApp()
+	deep := strings.Repeat("[", defaultMaxJSONDepth+1) + strings.Repeat("]", defaultMaxJSONDepth+1)
+
+	var v any
+	if err := bindWithApp(app, deep, &v); !errors.Is(err, ErrJSONTooComplex) {
+		t.Fatalf("default limit: got %v, want ErrJSONTooComplex", err)
+	}
+
+	app.SetMaxJSONDepth(4)
+	if err := bindWithApp(app, `{"a": {"b": {"c": {"d": {}}}}}`, &v); !errors.Is(err, ErrJSONTooComplex) {
+		t.Fatalf("depth 5 over 4: got %v, want ErrJSONTooComplex", err)
+	}
+	if err := bindWithApp(app, `{"a": {"b": {"c": {"d": 1}}}}`, &v); err != nil {
+		t.Fatalf("depth 4: unexpected error %v", err)
+	}
+}
+
+func TestBindRejectsTooManyElements(t *testing.T) {
+	app := newTestApp()
+	app.SetMaxJSONElements(3)
+
+	var v any
+	if err := bindWithApp(app, `{"list": [1, 2, 3, 4]}`, &v); !errors.Is(err, ErrJSONTooComplex) {
+		t.Fatalf("array: got %v, want ErrJSONTooComplex", err)
+	}
+	if err := bindWithApp(app, `{"a": 1, "b": 2, "c": 3, "d": 4}`, &v); !errors.Is(err, ErrJSONTooComplex) {
+		t.Fatalf("object: got %v, want ErrJSONTooComplex", err)
+	}
+	if err := bindWithApp(app, `{"list": [1, 2, 3]}`, &v); err != nil {
+		t.Fatalf("within limits: unexpected error %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package hikari
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PreferredLanguage returns the supported language that best matches the
+// Accept-Language header, honoring quality values. A tag also matches by its
+// base language ("pt-BR" matches "pt"). When nothing matches, the first
+// supported language is returned.
+func (c *Context) PreferredLanguage(supported ...string) string {
+	if len(supported) == 0 {
+		return ""
+	}
+
+	for _, tag := range parseAcceptLanguage(c.Request.Header.Get("Accept-Language")) {
+		if tag == "*" {
+			return supported[0]
+		}
+		for _, lang := range supported {
+			if strings.EqualFold(lang, tag) {
+				return lang
+			}
+		}
+		base, _, _ := strings.Cut(tag, "-")
+		for _, lang := range supported {
+			langBase, _, _ := strings.Cut(lang, "-")
+			if strings.EqualFold(langBase, base) {
+				return lang
+			}
+		}
+	}
+
+	return supported[0]
+}
+
+// parseAcceptLanguage returns the language tags of the header ordered by
+// quality, dropping those with q=0.
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag: tag, q: q})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+	return result
+}
@@ -1,6 +1,7 @@
 package hikari
 
 import (
+	"net/http"
 	"time"
 
 	"go.uber.org/zap"
@@ -22,14 +23,32 @@ func (a *App) loggerMiddleware(next HandlerFunc) HandlerFunc {
 		// Replace the context logger with the enriched one
 		c.Logger = reqLogger
 
-		reqLogger.Info("Request started")
+		if a.slowRequestThreshold == 0 {
+			reqLogger.Info("Request started")
+		}
 		next(c)
 
 		duration := time.Since(start)
 		status := c.GetStatus()
 
+		// Handlers may have added fields with LogWith
+		reqLogger = c.Logger
+		if len(c.middlewareTimings) > 0 {
+			reqLogger = reqLogger.With(zap.Objects("middleware_timings", c.middlewareTimings))
+		}
+
+		// In slow-only mode, fast successful requests aren't logged
+		if a.slowRequestThreshold > 0 && duration <= a.slowRequestThreshold && status < 400 {
+			return
+		}
+
 		// Choose log level based on status code
 		switch {
+		case a.quietFavicon && status == http.StatusNotFound && c.Path() == "/favicon.ico":
+			reqLogger.Debug("Request completed",
+				zap.Int("status", status),
+				zap.Duration("duration", duration),
+			)
 		case status >= 500:
 			reqLogger.Error("Request completed",
 				zap.Int("status", status),
@@ -0,0 +1,34 @@
+package hikari
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogWithFieldInCompletionLog(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	app := newTestApp()
+	app.logger = zap.New(core)
+
+	app.GET("/me", func(c *Context) {
+		c.LogWith(zap.String("user_id", "42"))
+		c.Logger.Info("Loaded profile")
+		c.String(http.StatusOK, "ok")
+	})
+	serve(app, httptest.NewRequest(http.MethodGet, "/me", nil))
+
+	for _, message := range []string{"Loaded profile", "Request completed"} {
+		entries := logs.FilterMessage(message).FilterField(zap.String("user_id", "42")).All()
+		if len(entries) != 1 {
+			t.Errorf("%q: %d entries with user_id, want 1", message, len(entries))
+		}
+	}
+	if n := logs.FilterMessage("Request started").FilterField(zap.String("user_id", "42")).Len(); n != 0 {
+		t.Errorf("user_id was logged before it was added")
+	}
+}
@@ -0,0 +1,47 @@
+package hikari
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// methodOverrideTargets are the methods a POST can be overridden to.
+var methodOverrideTargets = map[string]bool{
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// MethodOverride lets HTML forms reach PUT, PATCH and DELETE routes. It rewrites
+// the method of POST requests from the X-HTTP-Method-Override header or the
+// _method field of a form body. Other methods and override targets are ignored.
+// Register it with App.Use so it runs before routing:
+//
+//	app.Use(hikari.MethodOverride())
+//
+//	<form method="POST" action="/todos/1">
+//		<input type="hidden" name="_method" value="DELETE">
+//	</form>
+func MethodOverride() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			if c.Request.Method == http.MethodPost {
+				method := c.Request.Header.Get("X-HTTP-Method-Override")
+				if method == "" && isFormRequest(c.Request) {
+					method = c.Request.PostFormValue("_method")
+				}
+				if method = strings.ToUpper(strings.TrimSpace(method)); methodOverrideTargets[method] {
+					c.Request.Method = method
+				}
+			}
+			next(c)
+		}
+	}
+}
+
+// isFormRequest reports whether the request body is an HTML form.
+func isFormRequest(req *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	return err == nil && (mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data")
+}
@@ -1,3 +1,144 @@
 package hikari
 
+import (
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
 type Middleware func(HandlerFunc) HandlerFunc
+
+// Named gives a middleware a name so routes can opt out of it with Route.Skip.
+//
+// Middlewares are still wrapped in registration order when the chain is built;
+// a skipped middleware simply calls the next handler directly, so the order of
+// the remaining middlewares is unchanged. Global middlewares run before routing,
+// so the route is looked up when the first of them runs to know which names it
+// skips.
+func Named(name string, middleware Middleware) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		wrapped := middleware(timedNext(next))
+		return func(c *Context) {
+			if c.skipsMiddleware(name) {
+				next(c)
+				return
+			}
+			if c.app != nil && c.app.middlewareTrace {
+				c.middlewareTrace = append(c.middlewareTrace, name)
+			}
+			if c.app != nil && c.app.middlewareProfiling {
+				profileMiddleware(c, name, wrapped)
+				return
+			}
+			wrapped(c)
+		}
+	}
+}
+
+// skipsMiddleware reports whether the route matching the request skips the
+// Named middleware name. Before routing the route is looked up, only if some
+// route uses Route.Skip, and again if a middleware such as MethodOverride has
+// changed the method since.
+func (c *Context) skipsMiddleware(name string) bool {
+	if c.app == nil || !c.app.router.hasSkips {
+		return false
+	}
+	if c.skipsMethod != c.Request.Method {
+		c.skipMiddlewares, c.skipsMethod = nil, c.Request.Method
+		if !c.app.exceedsPathLimits(c.Request.URL.Path) {
+			if rt := c.app.router.lookup(c.Request.Method, c.Request.URL.Path); rt != nil {
+				c.skipMiddlewares = rt.skip
+			}
+		}
+	}
+	return c.skipMiddlewares[name]
+}
+
+// MiddlewareTiming is the time spent in a Named middleware itself during a
+// request, excluding the rest of the chain it called with next.
+type MiddlewareTiming struct {
+	Name     string
+	Duration time.Duration
+
+	// Time spent in next, subtracted from Duration
+	inNext time.Duration
+}
+
+func (t MiddlewareTiming) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("name", t.Name)
+	enc.AddDuration("duration", t.Duration)
+	return nil
+}
+
+// profileMiddleware runs wrapped, recording its timing in c. The index of the
+// running middleware is pushed on c.profileStack so timedNext knows whose
+// next call it is measuring.
+func profileMiddleware(c *Context, name string, wrapped HandlerFunc) {
+	i := len(c.middlewareTimings)
+	c.middlewareTimings = append(c.middlewareTimings, MiddlewareTiming{Name: name})
+	c.profileStack = append(c.profileStack, i)
+
+	start := time.Now()
+	wrapped(c)
+	total := time.Since(start)
+
+	c.profileStack = c.profileStack[:len(c.profileStack)-1]
+	c.middlewareTimings[i].Duration = total - c.middlewareTimings[i].inNext
+}
+
+// timedNext measures the time a profiled middleware spends in next.
+func timedNext(next HandlerFunc) HandlerFunc {
+	return func(c *Context) {
+		if len(c.profileStack) == 0 {
+			next(c)
+			return
+		}
+		i := c.profileStack[len(c.profileStack)-1]
+		start := time.Now()
+		next(c)
+		c.middlewareTimings[i].inNext += time.Since(start)
+	}
+}
+
+// MiddlewareFunc is a middleware written as a single function: it calls next
+// to run the rest of the chain and returns without calling it to stop there.
+// It is converted to a Middleware, which stays the canonical form.
+type MiddlewareFunc func(c *Context, next func())
+
+// Middleware converts fn to a Middleware.
+func (fn MiddlewareFunc) Middleware() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			fn(c, func() { next(c) })
+		}
+	}
+}
+
+// Chain composes middlewares into a single one running them in order, to reuse
+// a common stack across apps and groups:
+//
+//	api := hikari.Chain(cors, jsonContentType, requestID)
+//	app.Group("/api", api)
+func Chain(middlewares ...Middleware) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return chain(next, middlewares)
+	}
+}
+
+// chain wraps handler with middlewares so that the first middleware runs first.
+// Each next handler is skipped once the context has been aborted.
+func chain(handler HandlerFunc, middlewares []Middleware) HandlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](abortable(handler))
+	}
+	return handler
+}
+
+func abortable(next HandlerFunc) HandlerFunc {
+	return func(c *Context) {
+		if c.IsAborted() {
+			return
+		}
+		next(c)
+	}
+}
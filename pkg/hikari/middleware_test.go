@@ -0,0 +1,84 @@
+package hikari
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// recordMiddleware appends name to the "order" value before and after next.
+func recordMiddleware(name string) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			c.Set("order", c.GetString("order")+name+">")
+			next(c)
+			c.Set("order", c.GetString("order")+"<"+name)
+		}
+	}
+}
+
+func TestChainRunsInOrder(t *testing.T) {
+	app := newTestApp()
+	var order string
+	app.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			next(c)
+			order = c.GetString("order")
+		}
+	})
+
+	api := app.Group("/api", Chain(recordMiddleware("a"), recordMiddleware("b")))
+	api.GET("/items", func(c *Context) {
+		c.Set("order", c.GetString("order")+"handler")
+	}, Chain(recordMiddleware("c"), Chain(recordMiddleware("d"))))
+
+	serve(app, httptest.NewRequest(http.MethodGet, "/api/items", nil))
+	if want := "a>b>c>d>handler<d<c<b<a"; order != want {
+		t.Fatalf("order = %q, want %q", order, want)
+	}
+}
+
+func TestChainStopsOnAbort(t *testing.T) {
+	app := newTestApp()
+	deny := func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			c.JSON(http.StatusForbidden, H{"error": "forbidden"})
+			c.Abort()
+			next(c)
+		}
+	}
+	called := false
+	app.GET("/admin", func(c *Context) { called = true }, Chain(recordMiddleware("a"), deny, recordMiddleware("b")))
+
+	w := serve(app, httptest.NewRequest(http.MethodGet, "/admin", nil))
+	if called || w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "forbidden") {
+		t.Fatalf("called = %v, got %d %q", called, w.Code, w.Body.String())
+	}
+}
+
+func TestSkipUsesOverriddenMethod(t *testing.T) {
+	app := newTestApp()
+	app.Use(MethodOverride())
+	app.Use(Named("auth", func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			c.SetHeader("X-Auth", "checked")
+			next(c)
+		}
+	}))
+	app.POST("/todos/:id", func(c *Context) { c.String(http.StatusOK, "updated") })
+	app.DELETE("/todos/:id", func(c *Context) { c.String(http.StatusOK, "deleted") }).Skip("auth")
+
+	req := httptest.NewRequest(http.MethodPost, "/todos/1", nil)
+	if w := serve(app, req); w.Header().Get("X-Auth") != "checked" {
+		t.Fatalf("POST: auth skipped, body %q", w.Body.String())
+	}
+
+	// The route is looked up with the method set by MethodOverride
+	req = httptest.NewRequest(http.MethodPost, "/todos/1", nil)
+	req.Header.Set("X-HTTP-Method-Override", http.MethodDelete)
+	w := serve(app, req)
+	if w.Body.String() != "deleted" || w.Header().Get("X-Auth") != "" {
+		t.Fatalf("overridden DELETE: body %q, X-Auth %q", w.Body.String(), w.Header().Get("X-Auth"))
+	}
+}
@@ -6,19 +6,44 @@ import (
 	"go.uber.org/zap"
 )
 
+// RecoveryHandler writes the response for a request whose handler panicked with
+// recovered. It is only called if nothing has been written yet.
+type RecoveryHandler func(c *Context, recovered any)
+
 // Built-in Recovery Middleware (always applied first)
 func (a *App) recoveryMiddleware(next HandlerFunc) HandlerFunc {
 	return func(c *Context) {
 		defer func() {
 			if r := recover(); r != nil {
-				a.logger.Error("Request panic recovered",
-					zap.Any("panic", r),
-					zap.String("method", c.Method()),
-					zap.String("path", c.Path()),
-				)
-				http.Error(c.Writer, "Internal Server Error", http.StatusInternalServerError)
+				// c.Logger is enriched by the logger middleware, which runs inside
+				// recovery, so it already carries the request fields
+				logger := c.Logger
+				if logger == nil || a.loggerDisabled {
+					logger = a.logger.With(
+						zap.String("method", c.Method()),
+						zap.String("path", c.Path()),
+					)
+				}
+				logger.Error("Request panic recovered", zap.Any("panic", r))
+				if c.Written() {
+					return
+				}
+				if a.recoveryHandler != nil {
+					a.recoveryHandler(c, r)
+					return
+				}
+				a.defaultRecoveryResponse(c)
 			}
 		}()
 		next(c)
 	}
 }
+
+// defaultRecoveryResponse writes a 500 in the app's default response format.
+func (a *App) defaultRecoveryResponse(c *Context) {
+	if a.router.responseFormat == ResponseFormatJSON {
+		c.JSON(http.StatusInternalServerError, H{"error": "internal server error"})
+		return
+	}
+	http.Error(c.Writer, "Internal Server Error", http.StatusInternalServerError)
+}
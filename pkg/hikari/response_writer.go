@@ -6,11 +6,47 @@ import (
 	"net/http"
 )
 
+// ResponseWriter is the writer exposed as Context.Writer. Middleware can
+// replace c.Writer with its own implementation, usually by embedding
+// ResponseWriterWrapper and overriding the methods it needs.
+type ResponseWriter interface {
+	http.ResponseWriter
+	http.Hijacker
+	http.Flusher
+
+	// StatusCode returns the status sent, or 200 if none was sent yet
+	StatusCode() int
+	// Written reports whether the response headers have already been sent
+	Written() bool
+	// Unwrap returns the underlying writer, for http.ResponseController
+	Unwrap() http.ResponseWriter
+}
+
+// ResponseWriterWrapper forwards every method to the wrapped ResponseWriter.
+// Embed it in response-transforming middleware writers:
+//
+//	type gzipWriter struct {
+//		hikari.ResponseWriterWrapper
+//		gz *gzip.Writer
+//	}
+//
+//	func (w *gzipWriter) Write(b []byte) (int, error) { return w.gz.Write(b) }
+type ResponseWriterWrapper struct {
+	ResponseWriter
+}
+
+func (w *ResponseWriterWrapper) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // ResponseWriter wrapper to capture status code
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
 	written    bool
+
+	// Hooks registered with Context.OnResponse, run once before the headers are sent
+	beforeWrite []func()
 }
 
 func newResponseWriter(w http.ResponseWriter) *responseWriter {
@@ -25,6 +61,13 @@ func (rw *responseWriter) WriteHeader(code int) {
 	if !rw.written {
 		rw.statusCode = code
 		rw.written = true
+
+		hooks := rw.beforeWrite
+		rw.beforeWrite = nil
+		for _, hook := range hooks {
+			hook()
+		}
+
 		rw.ResponseWriter.WriteHeader(code)
 	}
 }
@@ -40,6 +83,29 @@ func (rw *responseWriter) StatusCode() int {
 	return rw.statusCode
 }
 
+// Written reports whether the response headers have already been sent
+func (rw *responseWriter) Written() bool {
+	return rw.written
+}
+
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// baseResponseWriter finds the framework's writer under any middleware wrappers.
+func baseResponseWriter(w http.ResponseWriter) (*responseWriter, bool) {
+	for {
+		switch writer := w.(type) {
+		case *responseWriter:
+			return writer, true
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = writer.Unwrap()
+		default:
+			return nil, false
+		}
+	}
+}
+
 // Hijack implements http.Hijacker interface for WebSocket support
 func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	if hijacker, ok := w.ResponseWriter.(http.Hijacker); ok {
@@ -2,48 +2,126 @@ package hikari
 
 import (
 	"net/http"
+	"slices"
 	"strings"
 
 	"go.uber.org/zap"
 )
 
 type route struct {
-	method      string
-	pattern     string
+	method  string
+	pattern string
+	// Segments of pattern, split once at registration
+	parts       []string
 	handler     HandlerFunc
 	middlewares []Middleware
+	meta        map[string]any
+	// Names of Named middlewares that are skipped for this route
+	skip map[string]bool
+	// Hub name for WebSocket routes
+	hub string
+	// Set by Route.Priority, higher wins when several routes match
+	priority int
+}
+
+// Route is returned by route registration and allows attaching metadata that
+// handlers and middlewares can read with Context.RouteMeta.
+type Route struct {
+	route  *route
+	router *router
+}
+
+// Set attaches a metadata value to the route.
+func (r *Route) Set(key string, value any) *Route {
+	if r.route.meta == nil {
+		r.route.meta = make(map[string]any)
+	}
+	r.route.meta[key] = value
+	return r
+}
+
+// Priority sets the precedence of the route when several routes match a
+// request, 0 by default. By default the most specific route wins: at the first
+// segment where two patterns differ, static beats param and param beats
+// wildcard, then an exact match beats an empty catch-all. A higher priority
+// wins over these rules; registration order only decides between routes
+// matching the same paths with the same priority, which is logged as a warning
+// when the server starts.
+func (r *Route) Priority(n int) *Route {
+	r.route.priority = n
+	return r
+}
+
+// Skip disables the middlewares registered with Named under the given names
+// for this route, including global ones registered with App.Use.
+func (r *Route) Skip(names ...string) *Route {
+	if r.route.skip == nil {
+		r.route.skip = make(map[string]bool, len(names))
+	}
+	for _, name := range names {
+		r.route.skip[name] = true
+	}
+	r.router.hasSkips = true
+	return r
 }
 
 type router struct {
-	routes []route
+	routes []*route
 	logger *zap.Logger
+
+	// Middlewares registered with App.UsePost, applied only after a route matches
+	postMiddlewares []Middleware
+
+	// Responses for requests that don't match any route
+	notFound         HandlerFunc
+	methodNotAllowed HandlerFunc
+	responseFormat   ResponseFormat
+
+	// Tried in order before the 404, each reports whether it handled the
+	// request. Added by App.SPA
+	fallbacks []func(*Context) bool
+
+	// Prefix prepended to every registered pattern, set by App.SetBasePath
+	basePath string
+
+	// Set once a route uses Route.Skip, global Named middlewares only look the
+	// route up when it is
+	hasSkips bool
 }
 
+// ResponseFormat selects the format of the framework's default error responses.
+type ResponseFormat int
+
+const (
+	ResponseFormatText ResponseFormat = iota
+	ResponseFormatJSON
+)
+
 func newRouter(logger *zap.Logger) *router {
 	return &router{
-		routes: []route{},
+		routes: []*route{},
 		logger: logger,
 	}
 }
 
-func (r *router) handle(method, pattern string, handler HandlerFunc, middlewares ...Middleware) {
+func (r *router) handle(method, pattern string, handler HandlerFunc, middlewares ...Middleware) *Route {
 	normalizedPattern := buildPattern("", pattern, r.logger)
-
-	r.routes = append(r.routes, route{
-		method:      method,
-		pattern:     normalizedPattern,
-		handler:     handler,
-		middlewares: middlewares,
-	})
+	return r.handleNormalized(method, normalizedPattern, handler, middlewares...)
 }
 
-func (r *router) handleNormalized(method, pattern string, handler HandlerFunc, middlewares ...Middleware) {
-	r.routes = append(r.routes, route{
+func (r *router) handleNormalized(method, pattern string, handler HandlerFunc, middlewares ...Middleware) *Route {
+	if r.basePath != "" {
+		pattern = normalizedPattern(r.basePath + pattern)
+	}
+	rt := &route{
 		method:      method,
 		pattern:     pattern,
+		parts:       splitPath(pattern),
 		handler:     handler,
 		middlewares: middlewares,
-	})
+	}
+	r.routes = append(r.routes, rt)
+	return &Route{route: rt, router: r}
 }
 
 func splitPath(p string) []string {
@@ -55,70 +133,229 @@ func splitPath(p string) []string {
 	return strings.Split(p, "/")
 }
 
-func (r *router) serveContext(ctx *Context) {
-	for _, rt := range r.routes {
-		if rt.method != ctx.Request.Method {
-			continue
+// hasWildcard reports whether the pattern ends with a catch-all (* or *name).
+func (rt *route) hasWildcard() bool {
+	return len(rt.parts) > 0 && strings.HasPrefix(rt.parts[len(rt.parts)-1], "*")
+}
+
+// match reports whether the request path parts match the route pattern. It
+// doesn't allocate, the parameters are only captured by params once the route
+// that takes precedence is known.
+func (rt *route) match(rParts []string) bool {
+	pParts := rt.parts
+
+	// Check parts up to wildcard (if any)
+	partsToCheck := len(pParts)
+	if rt.hasWildcard() {
+		partsToCheck = len(pParts) - 1
+		// For wildcard routes, we need at least as many parts as the pattern (minus the wildcard)
+		if len(rParts) < partsToCheck {
+			return false
+		}
+	} else if len(pParts) != len(rParts) {
+		// For non-wildcard routes, parts must match exactly
+		return false
+	}
+
+	for i := 0; i < partsToCheck; i++ {
+		if !strings.HasPrefix(pParts[i], ":") && pParts[i] != rParts[i] {
+			return false
 		}
+	}
+	return true
+}
 
-		requestPath := normalizedPattern(ctx.Request.URL.Path)
+// params returns the parameters captured from the path parts matched by the
+// route and the name of its catch-all parameter.
+func (rt *route) params(rParts []string) (map[string]string, string) {
+	pParts := rt.parts
+	partsToCheck := len(pParts)
+	if rt.hasWildcard() {
+		partsToCheck = len(pParts) - 1
+	}
 
-		pParts := splitPath(rt.pattern)
-		rParts := splitPath(requestPath)
+	params := map[string]string{}
+	for i := 0; i < partsToCheck; i++ {
+		if strings.HasPrefix(pParts[i], ":") {
+			params[pParts[i][1:]] = rParts[i]
+		}
+	}
 
-		// Check for wildcard pattern (*)
-		hasWildcard := len(pParts) > 0 && pParts[len(pParts)-1] == "*"
+	// If we have a wildcard, capture the remaining path
+	wildcardKey := ""
+	if rt.hasWildcard() {
+		wildcardKey = wildcardName(pParts[len(pParts)-1])
+		if len(rParts) > partsToCheck {
+			params[wildcardKey] = strings.Join(rParts[partsToCheck:], "/")
+		}
+	}
 
-		// For wildcard routes, we need at least as many parts as the pattern (minus the wildcard)
-		if hasWildcard {
-			if len(rParts) < len(pParts)-1 {
-				continue
-			}
-		} else {
-			// For non-wildcard routes, parts must match exactly
-			if len(pParts) != len(rParts) {
-				continue
-			}
+	return params, wildcardKey
+}
+
+// Segment kinds ordered by precedence when several routes match a path
+const (
+	segmentWildcard = iota
+	segmentParam
+	segmentStatic
+)
+
+func segmentKind(part string) int {
+	switch {
+	case strings.HasPrefix(part, "*"):
+		return segmentWildcard
+	case strings.HasPrefix(part, ":"):
+		return segmentParam
+	default:
+		return segmentStatic
+	}
+}
+
+// moreSpecific reports whether route a takes precedence over route b. At the
+// first position where their segments differ in kind, static beats param and
+// param beats wildcard.
+func moreSpecific(a, b *route) bool {
+	aParts, bParts := a.parts, b.parts
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aKind, bKind := segmentKind(aParts[i]), segmentKind(bParts[i])
+		if aKind != bKind {
+			return aKind > bKind
 		}
+	}
+	// Both matched the same path, so the extra segment of the longer one is a
+	// wildcard matching nothing, which is less specific than an exact match
+	return len(aParts) < len(bParts)
+}
 
-		params := map[string]string{}
-		matched := true
+// takesPrecedence reports whether route a wins over route b when both match.
+func takesPrecedence(a, b *route) bool {
+	if a.priority != b.priority {
+		return a.priority > b.priority
+	}
+	return moreSpecific(a, b)
+}
 
-		// Check parts up to wildcard (if any)
-		partsToCheck := len(pParts)
-		if hasWildcard {
-			partsToCheck = len(pParts) - 1
+// sameShape reports whether a and b match exactly the same paths, so neither
+// is more specific than the other.
+func sameShape(a, b *route) bool {
+	if len(a.parts) != len(b.parts) {
+		return false
+	}
+	for i := range a.parts {
+		aKind, bKind := segmentKind(a.parts[i]), segmentKind(b.parts[i])
+		if aKind != bKind || (aKind == segmentStatic && a.parts[i] != b.parts[i]) {
+			return false
 		}
+	}
+	return true
+}
 
-		for i := 0; i < partsToCheck; i++ {
-			if strings.HasPrefix(pParts[i], ":") {
-				params[strings.TrimPrefix(pParts[i], ":")] = rParts[i]
-			} else if pParts[i] != rParts[i] {
-				matched = false
-				break
+// warnAmbiguous logs a warning for each pair of routes that can't be told apart
+// but by registration order. It runs at startup, once priorities are set.
+func (r *router) warnAmbiguous() {
+	for i, rt := range r.routes {
+		for _, other := range r.routes[:i] {
+			if other.method == rt.method && other.priority == rt.priority && sameShape(other, rt) {
+				r.logger.Warn("Routes match the same paths with the same priority, the first registered wins",
+					zap.String("method", rt.method),
+					zap.String("pattern", rt.pattern),
+					zap.String("other", other.pattern),
+				)
 			}
 		}
+	}
+}
 
-		// If we have a wildcard, capture the remaining path
-		if matched && hasWildcard && len(rParts) > partsToCheck {
-			remainingParts := rParts[partsToCheck:]
-			params["*"] = strings.Join(remainingParts, "/")
+// find returns the route matching method and the path parts that takes
+// precedence, independent of registration order. When no route matches,
+// allowed lists the distinct methods of routes matching the path only.
+func (r *router) find(method string, rParts []string) (best *route, allowed []string) {
+	for _, rt := range r.routes {
+		if !rt.match(rParts) {
+			continue
 		}
 
-		if matched {
-			// Update the existing context with route parameters
-			ctx.Params = params
-
-			handler := rt.handler
-			// Apply user middlewares first (in reverse order)
-			for i := len(rt.middlewares) - 1; i >= 0; i-- {
-				handler = rt.middlewares[i](handler)
+		if rt.method != method {
+			if !slices.Contains(allowed, rt.method) {
+				allowed = append(allowed, rt.method)
 			}
+			continue
+		}
 
-			handler(ctx)
+		if best == nil || takesPrecedence(rt, best) {
+			best = rt
+		}
+	}
+	return best, allowed
+}
+
+// lookup returns the route matching method and path, or nil.
+func (r *router) lookup(method, path string) *route {
+	rt, _ := r.find(method, splitPath(normalizedPattern(path)))
+	return rt
+}
+
+func (r *router) serveContext(ctx *Context) {
+	requestPath := normalizedPattern(ctx.Request.URL.Path)
+	rParts := splitPath(requestPath)
+
+	// allowed holds the methods of routes matching the path only, for 405 responses
+	rt, allowed := r.find(ctx.Request.Method, rParts)
+
+	if rt != nil {
+		// Update the existing context with route parameters
+		params, wildcardKey := rt.params(rParts)
+		ctx.Params = params
+		ctx.route = rt.pattern
+		ctx.routeMeta = rt.meta
+		ctx.wildcardKey = wildcardKey
+		ctx.skipMiddlewares, ctx.skipsMethod = rt.skip, ctx.Request.Method
+
+		// Post-routing middlewares wrap the route middlewares
+		handler := chain(rt.handler, rt.middlewares)
+		handler = chain(handler, r.postMiddlewares)
+
+		handler(ctx)
+
+		return
+	}
 
+	if len(allowed) > 0 {
+		ctx.SetHeader("Allow", strings.Join(allowed, ", "))
+		if r.methodNotAllowed != nil {
+			r.methodNotAllowed(ctx)
 			return
 		}
+		r.writeMiss(ctx, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	for _, fallback := range r.fallbacks {
+		if fallback(ctx) {
+			return
+		}
+	}
+	r.serveNotFound(ctx)
+}
+
+// serveNotFound responds with the NotFound handler or the default 404.
+func (r *router) serveNotFound(ctx *Context) {
+	if r.notFound != nil {
+		r.notFound(ctx)
+		return
+	}
+	r.writeMiss(ctx, http.StatusNotFound, "not found")
+}
+
+// writeMiss writes the default 404/405 response in the configured format.
+func (r *router) writeMiss(ctx *Context, status int, message string) {
+	if r.responseFormat == ResponseFormatJSON {
+		ctx.JSON(status, H{"error": message, "path": ctx.Path()})
+		return
+	}
+	if status == http.StatusNotFound {
+		http.NotFound(ctx.Writer, ctx.Request)
+		return
 	}
-	http.NotFound(ctx.Writer, ctx.Request)
+	http.Error(ctx.Writer, http.StatusText(status), status)
 }
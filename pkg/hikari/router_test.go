@@ -0,0 +1,135 @@
+package hikari
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestInvalidRoutePatternPanics(t *testing.T) {
+	for _, pattern := range []string{"/foo/:/bar", "/foo bar", "/files/*/more", "/users/:1id"} {
+		t.Run(pattern, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("registering %q didn't panic", pattern)
+				}
+			}()
+			newTestApp().GET(pattern, func(c *Context) {})
+		})
+	}
+}
+
+func TestValidRoutePatterns(t *testing.T) {
+	app := newTestApp()
+	for _, pattern := range []string{"/", "/favicon.ico", "/users/:id", "/static/*", "/files/*path", "/api/v1/my-items_2"} {
+		app.GET(pattern, func(c *Context) {})
+	}
+}
+
+func TestMostSpecificRouteWinsInAnyOrder(t *testing.T) {
+	routes := []struct {
+		pattern string
+		name    string
+	}{
+		{"/static/*", "wildcard"},
+		{"/static/favicon.ico", "favicon"},
+		{"/static/:file", "param"},
+		{"/users/:id", "user"},
+		{"/users/me", "me"},
+	}
+	requests := map[string]string{
+		"/static/favicon.ico": "favicon",
+		"/static/app.js":      "param",
+		"/static/css/app.css": "wildcard",
+		"/users/42":           "user",
+		"/users/me":           "me",
+	}
+
+	for _, reversed := range []bool{false, true} {
+		app := newTestApp()
+		for i := range routes {
+			r := routes[i]
+			if reversed {
+				r = routes[len(routes)-1-i]
+			}
+			name := r.name
+			app.GET(r.pattern, func(c *Context) { c.String(http.StatusOK, "%s", name) })
+		}
+
+		for path, want := range requests {
+			w := serve(app, httptest.NewRequest(http.MethodGet, path, nil))
+			if got := w.Body.String(); got != want {
+				t.Errorf("reversed=%v %s: served by %q, want %q", reversed, path, got, want)
+			}
+		}
+	}
+}
+
+func TestAmbiguousRoutesWarnedAtStartup(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	app := newTestApp()
+	app.router.logger = zap.New(core)
+
+	handler := func(c *Context) {}
+	app.GET("/users/:id", handler)
+	app.GET("/users/:name", handler).Priority(1)
+	app.GET("/posts/:id", handler)
+	app.GET("/posts/:slug", handler)
+	app.POST("/posts/:slug", handler)
+	if logs.Len() != 0 {
+		t.Fatalf("%d warnings at registration, want none before startup", logs.Len())
+	}
+
+	// Only the posts routes are told apart by registration order alone
+	app.router.warnAmbiguous()
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("%d warnings, want 1", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["pattern"] != "/posts/:slug" || fields["other"] != "/posts/:id" {
+		t.Fatalf("warning fields = %v", fields)
+	}
+}
+
+func BenchmarkRouterMatchLastRoute(b *testing.B) {
+	r := newRouter(zap.NewNop())
+	handler := func(c *Context) {}
+	for i := 0; i < 50; i++ {
+		r.handle(http.MethodGet, fmt.Sprintf("/resource%d/:id/items/:item", i), handler)
+	}
+	// Other routes match the request too: one with the wrong method and a
+	// less specific one, only the parameters of the winner are captured
+	r.handle(http.MethodGet, "/resource49/*rest", handler)
+	r.handle(http.MethodPost, "/resource49/:id/items/:item", handler)
+	r.handle(http.MethodGet, "/resource49/:id/items/latest", handler)
+
+	rParts := splitPath("/resource49/42/items/7")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rt, _ := r.find(http.MethodGet, rParts)
+		rt.params(rParts)
+	}
+}
+
+func TestMethodNotAllowedListsEachMethodOnce(t *testing.T) {
+	app := newTestApp()
+	handler := func(c *Context) {}
+	app.GET("/users/:id", handler)
+	app.GET("/users/me", handler)
+	app.POST("/users/me", handler)
+
+	w := serve(app, httptest.NewRequest(http.MethodDelete, "/users/me", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d, want 405", w.Code)
+	}
+	if allow := w.Header().Get("Allow"); allow != "GET, POST" {
+		t.Fatalf("Allow = %q, want %q", allow, "GET, POST")
+	}
+}
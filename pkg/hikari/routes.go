@@ -0,0 +1,65 @@
+package hikari
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"go.uber.org/zap"
+)
+
+// PrintRoutes writes an aligned table of the registered routes to w, sorted by
+// pattern then method. WebSocket routes show the hub they belong to.
+func (a *App) PrintRoutes(w io.Writer) error {
+	routes := make([]*route, len(a.router.routes))
+	copy(routes, a.router.routes)
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].pattern != routes[j].pattern {
+			return routes[i].pattern < routes[j].pattern
+		}
+		return routes[i].method < routes[j].method
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATTERN\tMIDDLEWARES\tHUB")
+	for _, rt := range routes {
+		hub := "-"
+		if rt.hub != "" {
+			hub = rt.hub
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", rt.method, rt.pattern, len(rt.middlewares), hub)
+	}
+	return tw.Flush()
+}
+
+// SetPrintRoutesOnStartup prints the route table to stdout when the server
+// starts, instead of logging one line per route.
+func (a *App) SetPrintRoutesOnStartup(enabled bool) {
+	a.printRoutes = enabled
+}
+
+// logRoutes logs the configured routes and hubs at startup.
+func (a *App) logRoutes() {
+	if a.printRoutes {
+		a.PrintRoutes(os.Stdout)
+		return
+	}
+
+	for _, route := range a.router.routes {
+		a.logger.Info("HTTP route configured",
+			zap.String("method", route.method),
+			zap.String("pattern", route.pattern),
+		)
+	}
+
+	// Listing all configured WebSocket and HTTP routes
+	if a.wsManager != nil {
+		for _, hubName := range a.wsManager.HubNames() {
+			a.logger.Info("WebSocket Hub configured",
+				zap.String("hub", hubName),
+			)
+		}
+	}
+}
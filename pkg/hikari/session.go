@@ -0,0 +1,349 @@
+package hikari
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SessionStore persists session values by session ID. Get returns nil values
+// and no error when the session doesn't exist or has expired.
+type SessionStore interface {
+	Get(id string) (map[string]any, error)
+	Set(id string, values map[string]any, maxAge time.Duration) error
+	Delete(id string) error
+}
+
+type SessionConfig struct {
+	// Key signing the session ID in the cookie, required
+	Secret     []byte
+	CookieName string
+	CookiePath string
+	Secure     bool
+	SameSite   http.SameSite
+	// Lifetime of the cookie and of the stored values, renewed on each change
+	MaxAge time.Duration
+}
+
+func DefaultSessionConfig(secret []byte) *SessionConfig {
+	return &SessionConfig{
+		Secret:     secret,
+		CookieName: "session_id",
+		CookiePath: "/",
+		SameSite:   http.SameSiteLaxMode,
+		MaxAge:     24 * time.Hour,
+	}
+}
+
+// SessionData holds the values of the current client's session. Changes are
+// saved to the store after the handler returns.
+type SessionData struct {
+	mu        sync.Mutex
+	id        string
+	values    map[string]any
+	changed   bool
+	destroyed bool
+	// ID to delete from the store, set by Regenerate
+	oldID string
+}
+
+const sessionFlashesKey = "_flashes"
+
+// ID returns the session ID.
+func (s *SessionData) ID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.id
+}
+
+func (s *SessionData) Get(key string) (any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.values[key]
+	return value, ok
+}
+
+func (s *SessionData) GetString(key string) string {
+	value, _ := s.Get(key)
+	str, _ := value.(string)
+	return str
+}
+
+func (s *SessionData) Set(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+	s.changed = true
+}
+
+func (s *SessionData) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+	s.changed = true
+}
+
+// AddFlash adds a message kept until the next call to Flashes, typically on
+// the next request after a redirect.
+func (s *SessionData) AddFlash(message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[sessionFlashesKey] = append(flashes(s.values[sessionFlashesKey]), message)
+	s.changed = true
+}
+
+// Flashes returns the flash messages and removes them from the session.
+func (s *SessionData) Flashes() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	messages := flashes(s.values[sessionFlashesKey])
+	if len(messages) > 0 {
+		delete(s.values, sessionFlashesKey)
+		s.changed = true
+	}
+	return messages
+}
+
+// flashes reads the flash messages, which come back as []any from stores
+// serializing the values.
+func flashes(value any) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []any:
+		messages := make([]string, 0, len(v))
+		for _, item := range v {
+			if str, ok := item.(string); ok {
+				messages = append(messages, str)
+			}
+		}
+		return messages
+	}
+	return nil
+}
+
+// Regenerate gives the session a new ID, keeping its values. Call it on login
+// to prevent session fixation.
+func (s *SessionData) Regenerate() error {
+	id, err := generateSessionID()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.oldID == "" {
+		s.oldID = s.id
+	}
+	s.id = id
+	s.changed = true
+	return nil
+}
+
+// Destroy removes the session from the store and expires the cookie.
+func (s *SessionData) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values = make(map[string]any)
+	s.destroyed = true
+}
+
+// Session returns the session loaded by the Session middleware, or nil when
+// the middleware isn't installed.
+func (c *Context) Session() *SessionData {
+	value, _ := c.Get("session")
+	session, _ := value.(*SessionData)
+	return session
+}
+
+// Session loads the session whose ID is in the signed cookie from store and
+// exposes it through c.Session(). Changes made by the handler are saved after
+// it returns. The cookie is only sent once the session holds values, so
+// anonymous visitors don't create sessions. It panics if config has no Secret.
+func Session(store SessionStore, config *SessionConfig) Middleware {
+	if config == nil || len(config.Secret) == 0 {
+		panic("hikari: Session requires a SessionConfig with a Secret")
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			session := loadSession(c, store, config)
+			if session == nil {
+				return
+			}
+			c.Set("session", session)
+
+			// The cookie has to be set before the response is written
+			cookieSent := false
+			c.OnResponse(func() {
+				cookieSent = true
+				setSessionCookie(c, session, config)
+			})
+
+			next(c)
+
+			if !cookieSent && !c.Written() {
+				setSessionCookie(c, session, config)
+			}
+			saveSession(c, session, store, config)
+		}
+	}
+}
+
+// loadSession returns the client's session, or a new one. It writes a 500 and
+// returns nil when the store fails.
+func loadSession(c *Context, store SessionStore, config *SessionConfig) *SessionData {
+	if cookie, err := c.Request.Cookie(config.CookieName); err == nil {
+		if id, ok := verifySessionID(cookie.Value, config.Secret); ok {
+			values, err := store.Get(id)
+			if err != nil {
+				c.Error(http.StatusInternalServerError, err)
+				return nil
+			}
+			if values != nil {
+				return &SessionData{id: id, values: values}
+			}
+		}
+	}
+
+	id, err := generateSessionID()
+	if err != nil {
+		c.Error(http.StatusInternalServerError, err)
+		return nil
+	}
+	return &SessionData{id: id, values: make(map[string]any)}
+}
+
+func setSessionCookie(c *Context, session *SessionData, config *SessionConfig) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	cookie := &http.Cookie{
+		Name:     config.CookieName,
+		Path:     config.CookiePath,
+		Secure:   config.Secure,
+		HttpOnly: true,
+		SameSite: config.SameSite,
+	}
+	switch {
+	case session.destroyed:
+		cookie.MaxAge = -1
+	case session.changed:
+		// Rolling expiry: the cookie lifetime is renewed with the stored values
+		cookie.Value = signSessionID(session.id, config.Secret)
+		cookie.MaxAge = int(config.MaxAge.Seconds())
+	default:
+		return
+	}
+	http.SetCookie(c.Writer, cookie)
+}
+
+func saveSession(c *Context, session *SessionData, store SessionStore, config *SessionConfig) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if session.oldID != "" {
+		if err := store.Delete(session.oldID); err != nil {
+			c.Logger.Error("Failed to delete regenerated session", zap.Error(err))
+		}
+	}
+	if session.destroyed {
+		if err := store.Delete(session.id); err != nil {
+			c.Logger.Error("Failed to delete session", zap.Error(err))
+		}
+		return
+	}
+	if session.changed {
+		if err := store.Set(session.id, session.values, config.MaxAge); err != nil {
+			c.Logger.Error("Failed to save session", zap.Error(err))
+		}
+	}
+}
+
+func generateSessionID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// signSessionID returns the cookie value "id.signature".
+func signSessionID(id string, secret []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(id))
+	return id + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func verifySessionID(value string, secret []byte) (string, bool) {
+	id, _, ok := strings.Cut(value, ".")
+	if !ok || !hmac.Equal([]byte(signSessionID(id, secret)), []byte(value)) {
+		return "", false
+	}
+	return id, true
+}
+
+// MemorySessionStore keeps sessions in memory. Sessions are lost on restart and
+// not shared between instances, so it suits development and single instances.
+type MemorySessionStore struct {
+	mu       sync.RWMutex
+	sessions map[string]memorySession
+}
+
+type memorySession struct {
+	values    map[string]any
+	expiresAt time.Time
+}
+
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: make(map[string]memorySession)}
+}
+
+func (s *MemorySessionStore) Get(id string) (map[string]any, error) {
+	s.mu.RLock()
+	session, ok := s.sessions[id]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, nil
+	}
+	if !session.expiresAt.IsZero() && time.Now().After(session.expiresAt) {
+		s.Delete(id)
+		return nil, nil
+	}
+	return copyValues(session.values), nil
+}
+
+func (s *MemorySessionStore) Set(id string, values map[string]any, maxAge time.Duration) error {
+	session := memorySession{values: copyValues(values)}
+	if maxAge > 0 {
+		session.expiresAt = time.Now().Add(maxAge)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[id] = session
+	return nil
+}
+
+func (s *MemorySessionStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+	return nil
+}
+
+// copyValues copies the map so a request can't modify the stored session
+// before saving it.
+func copyValues(values map[string]any) map[string]any {
+	copied := make(map[string]any, len(values))
+	for k, v := range values {
+		copied[k] = v
+	}
+	return copied
+}
@@ -0,0 +1,69 @@
+package hikari
+
+import (
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// SPA serves a single-page application from fsys under urlPrefix. It is a
+// fallback for GET and HEAD requests matching no route, so registered routes
+// always take precedence and other methods keep getting 404 or 405. Paths that
+// map to a file in fsys are served as is, other paths without a file extension
+// get index so client-side routing works. Missing files with an extension,
+// like /assets/missing.js, get a 404, as do paths under excludePrefixes so
+// unknown API endpoints aren't answered with the app.
+//
+//	//go:embed dist
+//	var dist embed.FS
+//
+//	sub, _ := fs.Sub(dist, "dist")
+//	app.SPA("/", sub, "index.html", "/api")
+func (a *App) SPA(urlPrefix string, fsys fs.FS, index string, excludePrefixes ...string) {
+	prefix := path.Join("/", a.router.basePath, urlPrefix)
+	excluded := make([]string, len(excludePrefixes))
+	for i, p := range excludePrefixes {
+		excluded[i] = path.Join("/", a.router.basePath, p)
+	}
+
+	a.router.fallbacks = append(a.router.fallbacks, func(c *Context) bool {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			return false
+		}
+		requestPath := normalizedPattern(c.Request.URL.Path)
+		name, ok := pathUnder(requestPath, prefix)
+		if !ok {
+			return false
+		}
+		for _, p := range excluded {
+			if _, ok := pathUnder(requestPath, p); ok {
+				return false
+			}
+		}
+
+		if name != "" {
+			name = path.Clean(name)
+			if serveFSFile(c, fsys, name) {
+				return true
+			}
+		}
+		if path.Ext(name) != "" || !serveFSFile(c, fsys, index) {
+			c.notFound()
+		}
+		return true
+	})
+}
+
+// pathUnder reports whether the normalized requestPath is prefix or below it,
+// returning the rest of the path without its leading slash.
+func pathUnder(requestPath, prefix string) (string, bool) {
+	if prefix == "/" {
+		return strings.TrimPrefix(requestPath, "/"), true
+	}
+	if requestPath == prefix {
+		return "", true
+	}
+	rest, ok := strings.CutPrefix(requestPath, prefix+"/")
+	return rest, ok
+}
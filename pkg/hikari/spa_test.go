@@ -0,0 +1,74 @@
+package hikari
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSPAFallsBackToIndex(t *testing.T) {
+	dist := fstest.MapFS{
+		"index.html":    {Data: []byte("<html>app</html>")},
+		"assets/app.js": {Data: []byte("console.log('app')")},
+	}
+
+	app := newTestApp()
+	app.GET("/api/users", func(c *Context) { c.String(http.StatusOK, "users") })
+	app.SPA("/", dist, "index.html", "/api")
+
+	tests := []struct {
+		path string
+		code int
+		body string
+		ct   string
+	}{
+		{"/", http.StatusOK, "<html>app</html>", "text/html"},
+		{"/some/client/route", http.StatusOK, "<html>app</html>", "text/html"},
+		{"/assets/app.js", http.StatusOK, "console.log('app')", "javascript"},
+		{"/assets/missing.js", http.StatusNotFound, "", ""},
+		{"/api/users", http.StatusOK, "users", "text/plain"},
+		{"/api/unknown", http.StatusNotFound, "", ""},
+		{"/apiary", http.StatusOK, "<html>app</html>", "text/html"},
+	}
+	for _, tt := range tests {
+		w := serve(app, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.code {
+			t.Errorf("%s: status = %d, want %d", tt.path, w.Code, tt.code)
+			continue
+		}
+		if tt.body != "" && w.Body.String() != tt.body {
+			t.Errorf("%s: body = %q, want %q", tt.path, w.Body.String(), tt.body)
+		}
+		if ct := w.Header().Get("Content-Type"); !strings.Contains(ct, tt.ct) {
+			t.Errorf("%s: Content-Type = %q, want %q", tt.path, ct, tt.ct)
+		}
+	}
+}
+
+func TestSPAOnlyAnswersGETAndHEAD(t *testing.T) {
+	app := newTestApp()
+	app.GET("/api/users", func(c *Context) { c.String(http.StatusOK, "users") })
+	app.SPA("/", fstest.MapFS{"index.html": {Data: []byte("<html>app</html>")}}, "index.html", "/api")
+
+	tests := []struct {
+		method string
+		path   string
+		code   int
+	}{
+		{http.MethodPost, "/api/unknown", http.StatusNotFound},
+		{http.MethodDelete, "/anything", http.StatusNotFound},
+		{http.MethodPost, "/api/users", http.StatusMethodNotAllowed},
+		{http.MethodHead, "/some/client/route", http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := serve(app, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.code {
+			t.Errorf("%s %s: status = %d, want %d", tt.method, tt.path, w.Code, tt.code)
+		}
+		if allow := w.Header().Get("Allow"); tt.code == http.StatusNotFound && allow != "" {
+			t.Errorf("%s %s: Allow = %q on a 404", tt.method, tt.path, allow)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package hikari
+
+import (
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// Static serves the files of fsys under urlPrefix, such as os.DirFS("public")
+// or an embed.FS. Missing files get the app's not found response. When the
+// client accepts gzip and a precompressed <file>.gz sidecar exists, it is
+// served instead with Content-Encoding: gzip.
+//
+//	app.Static("/assets", os.DirFS("public"))
+func (a *App) Static(urlPrefix string, fsys fs.FS) *Route {
+	return a.GET(path.Join("/", urlPrefix, "*"), func(c *Context) {
+		name := c.Wildcard()
+		if name == "" || !serveFSFile(c, fsys, path.Clean(name)) {
+			c.notFound()
+		}
+	})
+}
+
+// serveFSFile serves the regular file name from fsys, preferring its .gz
+// sidecar when the client accepts gzip, and reports whether it exists.
+func serveFSFile(c *Context, fsys fs.FS, name string) bool {
+	if !strings.Contains(strings.Join(c.Writer.Header().Values("Vary"), ","), "Accept-Encoding") {
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+	}
+
+	if acceptsGzip(c.Request) {
+		if f, info, ok := openFSFile(fsys, name+".gz"); ok {
+			defer f.Close()
+			if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+				c.SetHeader("Content-Type", contentType)
+			}
+			c.SetHeader("Content-Encoding", "gzip")
+			serveFSContent(c, fsys, name+".gz", f, info)
+			return true
+		}
+	}
+
+	f, info, ok := openFSFile(fsys, name)
+	if !ok {
+		return false
+	}
+	defer f.Close()
+	serveFSContent(c, fsys, name, f, info)
+	return true
+}
+
+// openFSFile opens name if it is a regular file.
+func openFSFile(fsys fs.FS, name string) (fs.File, fs.FileInfo, bool) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, nil, false
+	}
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		f.Close()
+		return nil, nil, false
+	}
+	return f, info, true
+}
+
+func serveFSContent(c *Context, fsys fs.FS, name string, f fs.File, info fs.FileInfo) {
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			c.Error(http.StatusInternalServerError, err)
+			return
+		}
+		content = strings.NewReader(string(data))
+	}
+	http.ServeContent(c.Writer, c.Request, info.Name(), info.ModTime(), content)
+}
+
+// acceptsGzip reports whether the Accept-Encoding header allows gzip.
+func acceptsGzip(req *http.Request) bool {
+	for _, encoding := range strings.Split(req.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(encoding), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), "gzip") {
+			continue
+		}
+		q := strings.ReplaceAll(params, " ", "")
+		return q != "q=0" && q != "q=0.0" && q != "q=0.00" && q != "q=0.000"
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package hikari
+
+import (
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestStaticServesGzipSidecar(t *testing.T) {
+	app := newTestApp()
+	app.Static("/assets", fstest.MapFS{
+		"app.js":    {Data: []byte("console.log('plain')")},
+		"app.js.gz": {Data: []byte("gzipped bytes")},
+		"other.js":  {Data: []byte("no sidecar")},
+	})
+
+	req := httptest.NewRequest("GET", "/assets/app.js", nil)
+	req.Header.Set("Accept-Encoding", "br, gzip")
+	w := serve(app, req)
+	if w.Body.String() != "gzipped bytes" || w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("gzip request: body %q, Content-Encoding %q", w.Body.String(), w.Header().Get("Content-Encoding"))
+	}
+	if got := w.Header().Get("Content-Type"); got != "text/javascript; charset=utf-8" {
+		t.Fatalf("Content-Type = %q, want the type of app.js", got)
+	}
+	if w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("Vary = %q", w.Header().Get("Vary"))
+	}
+
+	// Clients refusing gzip and files without a sidecar get the plain file
+	req = httptest.NewRequest("GET", "/assets/app.js", nil)
+	req.Header.Set("Accept-Encoding", "gzip;q=0")
+	w = serve(app, req)
+	if w.Body.String() != "console.log('plain')" || w.Header().Get("Content-Encoding") != "" {
+		t.Fatalf("q=0 request: body %q", w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/assets/other.js", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	if w = serve(app, req); w.Body.String() != "no sidecar" {
+		t.Fatalf("no sidecar: body %q", w.Body.String())
+	}
+}
@@ -0,0 +1,121 @@
+package hikari
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Stream calls step repeatedly, flushing after each call, until it returns
+// false or the client goes away. It returns true if the client disconnected
+// before the stream ended, so the handler can stop producing data. The
+// server's write timeout is lifted for the response, but the app's request
+// timeout still applies: when it expires the stream stops, false is returned
+// and a timeout is logged. Exempt long-lived streams such as server-sent
+// events with ExemptFromTimeout.
+//
+//	c.Stream(func(w io.Writer) bool {
+//		event, ok := <-events
+//		if !ok {
+//			return false
+//		}
+//		fmt.Fprintf(w, "data: %s\n\n", event)
+//		return true
+//	})
+func (c *Context) Stream(step func(w io.Writer) bool) bool {
+	c.clearWriteDeadline()
+	for {
+		select {
+		case <-c.Done():
+			if err := c.Request.Context().Err(); err != nil {
+				c.logClientGone(err)
+				return true
+			}
+			if c.Logger != nil {
+				c.Logger.Warn("Stream stopped by the request timeout", zap.Error(c.Err()))
+			}
+			return false
+		default:
+		}
+
+		keepOpen := step(c.Writer)
+		c.Writer.Flush()
+		if !keepOpen {
+			return false
+		}
+	}
+}
+
+// ndjsonFlushInterval is how often NDJSONWriter flushes buffered lines.
+const ndjsonFlushInterval = 100 * time.Millisecond
+
+// NDJSONWriter writes newline-delimited JSON, one value per line, so clients
+// can process a large response incrementally. It is returned by Context.NDJSON.
+type NDJSONWriter struct {
+	c         *Context
+	encoder   *json.Encoder
+	lastFlush time.Time
+}
+
+// NDJSON starts a newline-delimited JSON response with the given status. Like
+// Stream, the response isn't cut by the server's write timeout:
+//
+//	out, err := c.NDJSON(http.StatusOK)
+//	if err != nil {
+//		return
+//	}
+//	for _, record := range records {
+//		if err := out.Write(record); err != nil {
+//			return
+//		}
+//	}
+func (c *Context) NDJSON(status int) (*NDJSONWriter, error) {
+	if c.Written() {
+		return nil, errors.New("response already written")
+	}
+	c.clearWriteDeadline()
+	c.Writer.Header().Set("Content-Type", "application/x-ndjson")
+	c.Writer.WriteHeader(status)
+	return &NDJSONWriter{c: c, encoder: json.NewEncoder(c.Writer), lastFlush: time.Now()}, nil
+}
+
+// Write encodes v on its own line. Lines are flushed to the client
+// periodically. It returns the context error once the client went away or the
+// request deadline expired, so the caller can stop producing records.
+func (w *NDJSONWriter) Write(v any) error {
+	if err := w.c.Err(); err != nil {
+		w.c.logClientGone(err)
+		return err
+	}
+	if err := w.encoder.Encode(v); err != nil {
+		return err
+	}
+	if time.Since(w.lastFlush) >= ndjsonFlushInterval {
+		w.Flush()
+	}
+	return nil
+}
+
+// Flush sends the buffered lines to the client immediately.
+func (w *NDJSONWriter) Flush() {
+	w.c.Writer.Flush()
+	w.lastFlush = time.Now()
+}
+
+// clearWriteDeadline lifts the server's write timeout for this response, so
+// long-lived streams aren't cut off. Writers not supporting it are ignored.
+func (c *Context) clearWriteDeadline() {
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+}
+
+// logClientGone logs at debug level that a response was cut short because the
+// client went away, which is expected and not worth an error.
+func (c *Context) logClientGone(err error) {
+	if c.Logger != nil {
+		c.Logger.Debug("Client gone, response aborted", zap.Error(err))
+	}
+}
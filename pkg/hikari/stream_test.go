@@ -0,0 +1,104 @@
+package hikari
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestStreamStopsWhenClientGoes(t *testing.T) {
+	ctx, disconnect := context.WithCancel(context.Background())
+	defer disconnect()
+
+	app := newTestApp()
+	steps := 0
+	clientGone := false
+	app.GET("/events", func(c *Context) {
+		clientGone = c.Stream(func(w io.Writer) bool {
+			steps++
+			fmt.Fprintf(w, "data: %d\n\n", steps)
+			if steps == 3 {
+				disconnect()
+			}
+			return steps < 100
+		})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(ctx)
+	w := serve(app, req)
+
+	if !clientGone {
+		t.Fatal("Stream didn't report the disconnect")
+	}
+	if steps != 3 {
+		t.Fatalf("Stream ran %d steps, want 3", steps)
+	}
+	if !w.Flushed {
+		t.Fatal("Stream didn't flush")
+	}
+}
+
+func TestStreamEndsNormally(t *testing.T) {
+	app := newTestApp()
+	clientGone := true
+	app.GET("/count", func(c *Context) {
+		n := 0
+		clientGone = c.Stream(func(w io.Writer) bool {
+			n++
+			fmt.Fprintf(w, "%d\n", n)
+			return n < 3
+		})
+	})
+
+	w := serve(app, httptest.NewRequest(http.MethodGet, "/count", nil))
+	if clientGone || w.Body.String() != "1\n2\n3\n" {
+		t.Fatalf("clientGone = %v, body = %q", clientGone, w.Body.String())
+	}
+}
+
+func TestJSONSkipsDisconnectedClient(t *testing.T) {
+	ctx, disconnect := context.WithCancel(context.Background())
+	disconnect()
+
+	app := newTestApp()
+	app.GET("/report", func(c *Context) {
+		c.JSON(http.StatusOK, H{"rows": 1000})
+	})
+
+	w := serve(app, httptest.NewRequest(http.MethodGet, "/report", nil).WithContext(ctx))
+	if w.Body.Len() != 0 {
+		t.Fatalf("JSON wrote %q to a disconnected client", w.Body.String())
+	}
+}
+
+func TestStreamTimeoutIsNotADisconnect(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	app := newTestApp()
+	app.logger = zap.New(core)
+	app.SetRequestTimeout(30 * time.Millisecond)
+
+	clientGone := true
+	app.GET("/events", func(c *Context) {
+		clientGone = c.Stream(func(w io.Writer) bool {
+			time.Sleep(5 * time.Millisecond)
+			fmt.Fprint(w, "data: tick\n\n")
+			return true
+		})
+	})
+	serve(app, httptest.NewRequest(http.MethodGet, "/events", nil))
+
+	if clientGone {
+		t.Fatal("Stream reported the request timeout as a client disconnect")
+	}
+	if logs.FilterMessage("Stream stopped by the request timeout").Len() != 1 {
+		t.Fatal("request timeout not logged")
+	}
+}
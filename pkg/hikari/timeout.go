@@ -0,0 +1,39 @@
+package hikari
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Timeout replaces the request deadline with d for the wrapped handlers. The
+// new deadline replaces the app's request timeout, so it can be longer, while
+// values set on the context by earlier middlewares, such as tracing spans, are
+// kept. The context is still cancelled when the client goes away. If the
+// deadline expires before the handler writes a response, a 504 is written
+// through the error handler.
+func Timeout(d time.Duration) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			if c.IsWebSocket() {
+				next(c)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Context), d)
+			defer cancel()
+			stop := context.AfterFunc(c.Request.Context(), cancel)
+			defer stop()
+
+			parent := c.Context
+			c.Context = ctx
+			next(c)
+			c.Context = parent
+
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Written() {
+				c.Error(http.StatusGatewayTimeout, errors.New("request timeout"))
+			}
+		}
+	}
+}
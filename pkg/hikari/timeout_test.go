@@ -0,0 +1,116 @@
+package hikari
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func slowHandler(d time.Duration) HandlerFunc {
+	return func(c *Context) {
+		if err := c.Wait(d); err != nil {
+			return
+		}
+		c.String(http.StatusOK, "done")
+	}
+}
+
+func TestGroupTimeoutOutlivesAppTimeout(t *testing.T) {
+	app := newTestApp()
+	app.SetRequestTimeout(20 * time.Millisecond)
+
+	reports := app.Group("/reports")
+	reports.SetTimeout(time.Second)
+	reports.GET("/yearly", slowHandler(100*time.Millisecond))
+
+	w := serve(app, httptest.NewRequest(http.MethodGet, "/reports/yearly", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "done" {
+		t.Fatalf("got %d %q, want 200 from the long-timeout group", w.Code, w.Body.String())
+	}
+}
+
+func TestGroupTimeoutResponds504(t *testing.T) {
+	app := newTestApp()
+
+	api := app.Group("/api")
+	api.SetTimeout(20 * time.Millisecond)
+	api.GET("/slow", slowHandler(time.Second))
+
+	w := serve(app, httptest.NewRequest(http.MethodGet, "/api/slow", nil))
+	if w.Code != http.StatusGatewayTimeout {
+		t.Fatalf("status = %d, want 504", w.Code)
+	}
+}
+
+func TestExemptFromTimeout(t *testing.T) {
+	app := newTestApp()
+	app.SetRequestTimeout(20 * time.Millisecond)
+	app.ExemptFromTimeout("/events", "/download/*")
+
+	for _, path := range []string{"/events", "/download/*", "/report"} {
+		app.GET(path, slowHandler(100*time.Millisecond))
+	}
+
+	tests := map[string]string{
+		"/events":             "done",
+		"/download/2024/a.gz": "done",
+		"/report":             "",
+	}
+	for path, want := range tests {
+		w := serve(app, httptest.NewRequest(http.MethodGet, path, nil))
+		if got := w.Body.String(); got != want {
+			t.Errorf("%s: body = %q, want %q", path, got, want)
+		}
+	}
+}
+
+type timeoutTestKey struct{}
+
+func TestGroupTimeoutKeepsContextValues(t *testing.T) {
+	app := newTestApp()
+	app.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			c.Context = c.WithValue(timeoutTestKey{}, "trace-1")
+			next(c)
+		}
+	})
+
+	api := app.Group("/api")
+	api.SetTimeout(time.Second)
+	api.GET("/value", func(c *Context) {
+		value, _ := c.Value(timeoutTestKey{}).(string)
+		c.String(http.StatusOK, "%s", value)
+	})
+
+	w := serve(app, httptest.NewRequest(http.MethodGet, "/api/value", nil))
+	if w.Body.String() != "trace-1" {
+		t.Fatalf("body = %q, want the value set before SetTimeout", w.Body.String())
+	}
+}
+
+func TestGroupTimeoutCancelledWhenClientGoes(t *testing.T) {
+	app := newTestApp()
+	api := app.Group("/api")
+	api.SetTimeout(time.Second)
+
+	errs := make(chan error, 1)
+	api.GET("/wait", func(c *Context) {
+		<-c.Done()
+		errs <- c.Err()
+	})
+
+	reqCtx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/api/wait", nil).WithContext(reqCtx)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	serve(app, req)
+
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Fatalf("handler context error = %v, want context.Canceled", err)
+	}
+}
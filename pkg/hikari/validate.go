@@ -0,0 +1,135 @@
+package hikari
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ValidationError is returned by Validate and BindAndValidate. Fields maps the
+// dotted JSON path of each invalid field, e.g. "user.address.zip" or
+// "items.2.name", to the reason it failed.
+type ValidationError struct {
+	Fields map[string]string
+}
+
+func (e *ValidationError) Error() string {
+	paths := make([]string, 0, len(e.Fields))
+	for path := range e.Fields {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var b strings.Builder
+	b.WriteString("validation failed: ")
+	for i, path := range paths {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(path + " " + e.Fields[path])
+	}
+	return b.String()
+}
+
+// BindAndValidate binds the JSON body into v like Bind, then checks it with
+// Validate.
+func (c *Context) BindAndValidate(v any) error {
+	if err := c.Bind(v); err != nil {
+		return err
+	}
+	return Validate(v)
+}
+
+// Validate checks the `validate:"required"` tags of v, a struct or a pointer to
+// one, walking nested structs, pointers and slices. Required fields fail when
+// they hold their zero value, or are empty for slices and maps. It returns a
+// *ValidationError keyed by the fields' JSON paths, or nil.
+func Validate(v any) error {
+	fields := make(map[string]string)
+	validateValue(reflect.ValueOf(v), "", fields)
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+func validateValue(rv reflect.Value, path string, fields map[string]string) {
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		validateStruct(rv, path, fields)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			validateValue(rv.Index(i), joinPath(path, strconv.Itoa(i)), fields)
+		}
+	}
+}
+
+func validateStruct(rv reflect.Value, path string, fields map[string]string) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		value := rv.Field(i)
+
+		// Embedded structs are flattened by encoding/json, so are their paths,
+		// and their exported fields count even when the struct type doesn't
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			validateValue(value, path, fields)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		name := jsonFieldName(field)
+		if name == "-" {
+			continue
+		}
+		fieldPath := joinPath(path, name)
+
+		if hasRule(field.Tag.Get("validate"), "required") && isEmptyValue(value) {
+			fields[fieldPath] = "is required"
+			continue
+		}
+		validateValue(value, fieldPath, fields)
+	}
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func hasRule(tag, rule string) bool {
+	for _, r := range strings.Split(tag, ",") {
+		if strings.TrimSpace(r) == rule {
+			return true
+		}
+	}
+	return false
+}
+
+func isEmptyValue(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	}
+	return rv.IsZero()
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,73 @@
+package hikari
+
+import (
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type address struct {
+	Street string `json:"street" validate:"required"`
+	Zip    string `json:"zip" validate:"required"`
+}
+
+type orderItem struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type audit struct {
+	CreatedBy string `json:"created_by" validate:"required"`
+}
+
+type signup struct {
+	audit
+	User struct {
+		Name    string   `json:"name" validate:"required"`
+		Address *address `json:"address" validate:"required"`
+	} `json:"user"`
+	Items []orderItem `json:"items" validate:"required"`
+}
+
+func TestValidateNestedPaths(t *testing.T) {
+	var s signup
+	s.CreatedBy = "admin"
+	s.User.Name = "Ana"
+	s.User.Address = &address{Street: "Rua A"}
+	s.Items = []orderItem{{Name: "book"}, {}}
+
+	var verr *ValidationError
+	if err := Validate(&s); !errors.As(err, &verr) {
+		t.Fatalf("Validate = %v, want a *ValidationError", err)
+	}
+	want := map[string]string{
+		"user.address.zip": "is required",
+		"items.1.name":     "is required",
+	}
+	if !reflect.DeepEqual(verr.Fields, want) {
+		t.Fatalf("Fields = %v, want %v", verr.Fields, want)
+	}
+}
+
+func TestBindAndValidate(t *testing.T) {
+	body := `{"user":{"name":"Ana"},"items":[]}`
+	c := &Context{Request: httptest.NewRequest("POST", "/", strings.NewReader(body))}
+
+	var s signup
+	var verr *ValidationError
+	if err := c.BindAndValidate(&s); !errors.As(err, &verr) {
+		t.Fatalf("BindAndValidate = %v, want a *ValidationError", err)
+	}
+	want := map[string]string{
+		"created_by":   "is required",
+		"user.address": "is required",
+		"items":        "is required",
+	}
+	if !reflect.DeepEqual(verr.Fields, want) {
+		t.Fatalf("Fields = %v, want %v", verr.Fields, want)
+	}
+	if got := verr.Error(); got != "validation failed: created_by is required; items is required; user.address is required" {
+		t.Fatalf("Error() = %q", got)
+	}
+}
@@ -1,11 +1,15 @@
 package hikari
 
 import (
+	"compress/flate"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
-	"math/rand"
 	"net/http"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -23,6 +27,14 @@ type WebSocketConnection struct {
 	ctx     context.Context
 	cancel  context.CancelFunc
 	request *http.Request
+	// Set with WSContext.Tag, guarded by mu
+	tags map[string]struct{}
+
+	// Closed by CloseGracefully to make writePump drain the send buffer within
+	// flushTimeout, and by writePump when it returns
+	flush        chan struct{}
+	flushTimeout time.Duration
+	done         chan struct{}
 }
 
 type WebSocketHub struct {
@@ -35,14 +47,58 @@ type WebSocketHub struct {
 	logger      *zap.Logger
 	ctx         context.Context
 	cancel      context.CancelFunc
+
+	// Ring buffer of the last broadcast messages, guarded by mu
+	history     [][]byte
+	historySize int
+	historyNext int
+
+	// Presence events for Events subscribers, sent without blocking
+	events chan HubEvent
+
+	// Wrap the handler of every message, guarded by mu
+	middlewares []WSMiddleware
+
+	// Per-key state of BroadcastThrottled
+	throttleMu sync.Mutex
+	throttles  map[string]*broadcastThrottle
+
+	// Connections being upgraded or served, checked against maxConnections
+	active         atomic.Int64
+	maxConnections int
 }
 
+// HubEventType tells whether a HubEvent is a connection or a disconnection.
+type HubEventType string
+
+const (
+	HubEventConnected    HubEventType = "connected"
+	HubEventDisconnected HubEventType = "disconnected"
+)
+
+// HubEvent is emitted on WebSocketHub.Events as connections come and go.
+type HubEvent struct {
+	Type   HubEventType
+	ConnID string
+	Time   time.Time
+}
+
+// hubEventBuffer is the number of events kept for a slow Events subscriber.
+const hubEventBuffer = 256
+
 type WebSocketManager struct {
 	config   *WebSocketConfig
 	upgrader websocket.Upgrader
 	hubs     map[string]*WebSocketHub
 	mu       sync.RWMutex
 	logger   *zap.Logger
+
+	// Validated WebSocketConfig.CompressionLevel, 0 keeps the default
+	compressionLevel int
+
+	// Parent of every hub context, cancelled when the app shuts down
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 func NewWebSocketManager(config *WebSocketConfig, logger *zap.Logger) *WebSocketManager {
@@ -50,8 +106,20 @@ func NewWebSocketManager(config *WebSocketConfig, logger *zap.Logger) *WebSocket
 		config = DefaultWebSocketConfig()
 	}
 
+	compressionLevel := config.CompressionLevel
+	if compressionLevel < flate.HuffmanOnly || compressionLevel > flate.BestCompression {
+		logger.Warn("Invalid WebSocket compression level, using the default",
+			zap.Int("compression_level", compressionLevel))
+		compressionLevel = 0
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
 	return &WebSocketManager{
-		config: config,
+		ctx:              ctx,
+		cancel:           cancel,
+		config:           config,
+		compressionLevel: compressionLevel,
 		upgrader: websocket.Upgrader{
 			ReadBufferSize:    config.ReadBufferSize,
 			WriteBufferSize:   config.WriteBufferSize,
@@ -68,7 +136,7 @@ func (wm *WebSocketManager) RegisterHub(name string) *WebSocketHub {
 	wm.mu.Lock()
 	defer wm.mu.Unlock()
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(wm.ctx)
 	hub := &WebSocketHub{
 		name:        name,
 		connections: make(map[string]*WebSocketConnection),
@@ -78,6 +146,10 @@ func (wm *WebSocketManager) RegisterHub(name string) *WebSocketHub {
 		logger:      wm.logger.With(zap.String("hub", name)),
 		ctx:         ctx,
 		cancel:      cancel,
+		historySize: wm.config.HistorySize,
+		events:      make(chan HubEvent, hubEventBuffer),
+
+		maxConnections: wm.config.MaxConnections,
 	}
 
 	wm.hubs[name] = hub
@@ -93,6 +165,19 @@ func (wm *WebSocketManager) GetHub(name string) (*WebSocketHub, bool) {
 	return hub, ok
 }
 
+// HubNames returns the names of the registered hubs, sorted.
+func (wm *WebSocketManager) HubNames() []string {
+	wm.mu.RLock()
+	defer wm.mu.RUnlock()
+	names := make([]string, 0, len(wm.hubs))
+	for name := range wm.hubs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RemoveHub stops the hub and closes its connections.
 func (wm *WebSocketManager) RemoveHub(name string) {
 	wm.mu.Lock()
 	defer wm.mu.Unlock()
@@ -109,11 +194,33 @@ func (wm *WebSocketManager) Upgrade(c *Context, hubName string, handler WebSocke
 		hub = wm.RegisterHub(hubName)
 	}
 
+	// The slot is held for the lifetime of the connection, Upgrade only
+	// returns once it has ended
+	active := hub.active.Add(1)
+	defer hub.active.Add(-1)
+	if hub.maxConnections > 0 && active > int64(hub.maxConnections) {
+		wm.logger.Warn("WebSocket hub full, upgrade rejected",
+			zap.String("hub", hubName),
+			zap.Int("max_connections", hub.maxConnections),
+		)
+		return ErrHubFull
+	}
+
 	conn, err := wm.upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		wm.logger.Error("WebSocket upgrade failed", zap.Error(err))
 		return err
 	}
+	if wm.config.EnableCompression && wm.compressionLevel != 0 {
+		if err := conn.SetCompressionLevel(wm.compressionLevel); err != nil {
+			wm.logger.Warn("Failed to set WebSocket compression level", zap.Error(err))
+		}
+	}
+
+	// The connection context is derived from the hub, not from the HTTP
+	// request, so it ends with the connection or when the hub shuts down
+	connCtx, connCancel := context.WithCancel(hub.ctx)
+	defer connCancel()
 
 	connId := generateConnectionID()
 	wsConn := &WebSocketConnection{
@@ -123,7 +230,10 @@ func (wm *WebSocketManager) Upgrade(c *Context, hubName string, handler WebSocke
 		id:      connId,
 		logger:  wm.logger.With(zap.String("conn_id", connId)),
 		request: c.Request,
-		ctx:     c,
+		ctx:     connCtx,
+		cancel:  connCancel,
+		flush:   make(chan struct{}),
+		done:    make(chan struct{}),
 	}
 	select {
 	case hub.register <- wsConn:
@@ -131,6 +241,9 @@ func (wm *WebSocketManager) Upgrade(c *Context, hubName string, handler WebSocke
 	case <-time.After(wm.config.RegisterTimeout):
 		conn.Close()
 		return fmt.Errorf("failed to register connection: timeout")
+	case <-hub.ctx.Done():
+		conn.Close()
+		return fmt.Errorf("failed to register connection: hub closed")
 	}
 	wm.logger.Info("WebSocket connection established", zap.String("conn_id", connId))
 
@@ -143,12 +256,11 @@ func (wm *WebSocketManager) Upgrade(c *Context, hubName string, handler WebSocke
 }
 
 func (h *WebSocketHub) run() {
+	// The channels are left open: senders select on h.ctx.Done() instead, so a
+	// connection ending after the hub stopped doesn't send on a closed channel
 	defer func() {
 		h.logger.Info("WebSocket hub shutting down")
 		h.cancel()
-		close(h.register)
-		close(h.unregister)
-		close(h.broadcast)
 	}()
 
 	for {
@@ -158,29 +270,43 @@ func (h *WebSocketHub) run() {
 
 		case conn := <-h.register:
 			h.mu.Lock()
+			// IDs are random, but never let a new connection replace another one
+			for _, exists := h.connections[conn.id]; exists; _, exists = h.connections[conn.id] {
+				conn.id = generateConnectionID()
+				conn.logger = h.logger.With(zap.String("conn_id", conn.id))
+			}
 			h.connections[conn.id] = conn
+			// Replay the history to the new connection only
+			for _, message := range h.historyLocked() {
+				select {
+				case conn.send <- message:
+				default:
+				}
+			}
 			h.mu.Unlock()
 			h.logger.Info("WebSocket connection registered", zap.String("conn_id", conn.id))
+			h.emit(HubEventConnected, conn.id)
 
 		case conn := <-h.unregister:
 			h.mu.Lock()
 			if _, ok := h.connections[conn.id]; ok {
 				delete(h.connections, conn.id)
-				close(conn.send)
+				conn.closeSend()
 				h.logger.Info("WebSocket connection unregistered", zap.String("conn_id", conn.id))
+				h.emit(HubEventDisconnected, conn.id)
 			}
 			h.mu.Unlock()
 
 		case message := <-h.broadcast:
+			if h.historySize > 0 {
+				h.mu.Lock()
+				h.recordLocked(message)
+				h.mu.Unlock()
+			}
+
 			h.mu.RLock()
 			for _, conn := range h.connections {
-				select {
-				case conn.send <- message:
-				default:
-					close(conn.send)
-					delete(h.connections, conn.id)
-					h.logger.Warn("WebSocket connection send buffer full, connection closed")
-				}
+				conn.trySend(message)
 			}
 			h.mu.RUnlock()
 		}
@@ -189,7 +315,12 @@ func (h *WebSocketHub) run() {
 
 func (c *WebSocketConnection) readPump(config *WebSocketConfig, handler WebSocketHandler, originalContext *Context) {
 	defer func() {
-		c.hub.unregister <- c
+		// Stops writePump and tells handlers still running that the connection ended
+		c.cancel()
+		select {
+		case c.hub.unregister <- c:
+		case <-c.hub.ctx.Done():
+		}
 		c.conn.Close()
 		c.logger.Info("WebSocket connection closed")
 	}()
@@ -200,6 +331,12 @@ func (c *WebSocketConnection) readPump(config *WebSocketConfig, handler WebSocke
 		return nil
 	})
 
+	var limiter *tokenBucket
+	if config.MaxMessagesPerSecond > 0 {
+		limiter = newTokenBucket(config.MaxMessagesPerSecond, config.MessageBurst)
+	}
+	violations := 0
+
 	for {
 		select {
 		case <-c.ctx.Done():
@@ -213,7 +350,23 @@ func (c *WebSocketConnection) readPump(config *WebSocketConfig, handler WebSocke
 				return
 			}
 
+			if limiter != nil && !limiter.allow() {
+				violations++
+				c.logger.Warn("WebSocket rate limit exceeded, message dropped",
+					zap.String("conn_id", c.id),
+					zap.Int("violations", violations),
+				)
+				if config.MaxRateViolations > 0 && violations >= config.MaxRateViolations {
+					c.conn.WriteControl(websocket.CloseMessage,
+						websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "rate limit exceeded"),
+						time.Now().Add(time.Second))
+					return
+				}
+				continue
+			}
+
 			if handler != nil {
+				handler := c.hub.wrap(handler)
 				wsContext := &WSContext{
 					Context:     originalContext,
 					connection:  c,
@@ -240,17 +393,25 @@ func (c *WebSocketConnection) readPump(config *WebSocketConfig, handler WebSocke
 
 func (c *WebSocketConnection) writePump(config *WebSocketConfig) {
 	ticker := time.NewTicker(config.PingInterval)
+	writeWait := config.WriteWait
+	if writeWait <= 0 {
+		writeWait = 10 * time.Second
+	}
 	defer func() {
 		ticker.Stop()
 		c.conn.Close()
+		close(c.done)
 	}()
 
 	for {
 		select {
 		case <-c.ctx.Done():
 			return
+		case <-c.flush:
+			c.drain()
+			return
 		case message, ok := <-c.send:
-			c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if !ok {
 				// The hub closed the channel.
 				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
@@ -263,7 +424,7 @@ func (c *WebSocketConnection) writePump(config *WebSocketConfig) {
 			}
 
 		case <-ticker.C:
-			c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
 				c.logger.Error("WebSocket ping error", zap.Error(err))
 				return
@@ -272,35 +433,110 @@ func (c *WebSocketConnection) writePump(config *WebSocketConfig) {
 	}
 }
 
+// drain writes the messages already queued on the connection, then a normal
+// close frame, giving up once flushTimeout has elapsed.
+func (c *WebSocketConnection) drain() {
+	deadline := time.Now().Add(c.flushTimeout)
+	c.conn.SetWriteDeadline(deadline)
+	for {
+		select {
+		case message, ok := <-c.send:
+			if !ok {
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				c.logger.Warn("WebSocket write error while draining", zap.Error(err))
+				return
+			}
+		default:
+			closeMessage := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+			if err := c.conn.WriteControl(websocket.CloseMessage, closeMessage, deadline); err != nil {
+				c.logger.Warn("WebSocket close frame failed", zap.Error(err))
+			}
+			return
+		}
+	}
+}
+
+// Context returns the connection context, derived from the hub context and
+// cancelled when the connection is closed or the hub shuts down.
+func (c *WebSocketConnection) Context() context.Context {
+	return c.ctx
+}
+
 func (c *WebSocketConnection) Send(message []byte) error {
+	c.trySend(message)
+	return nil
+}
+
+// trySend queues message on the connection and reports whether it was queued.
+// A connection whose send buffer is full is closed, the hub then unregisters
+// it and closes its send channel.
+func (c *WebSocketConnection) trySend(message []byte) bool {
 	c.mu.RLock()
-	defer c.mu.RUnlock()
 	if c.closed {
-		return nil
+		c.mu.RUnlock()
+		return false
 	}
 	select {
 	case c.send <- message:
-		return nil
+		c.mu.RUnlock()
+		return true
 	default:
-		close(c.send)
-		c.closed = true
-		c.logger.Warn("WebSocket send buffer full, message dropped", zap.String("hub", c.hub.name))
-		return nil
 	}
+	c.mu.RUnlock()
+
+	c.logger.Warn("WebSocket send buffer full, connection closed", zap.String("hub", c.hub.name))
+	c.Close()
+	return false
 }
 
+// closeSend closes the send channel once the hub has unregistered the
+// connection. The hub is the only place closing it, and closed stops trySend
+// from sending on it afterwards.
+func (c *WebSocketConnection) closeSend() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	close(c.send)
+}
+
+// Close closes the connection immediately, discarding queued messages. The
+// send channel is closed by the hub once readPump unregisters the connection.
 func (c *WebSocketConnection) Close() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	if !c.closed {
 		c.closed = true
 		c.cancel()
-		close(c.send)
 		c.conn.Close()
 		c.logger.Info("WebSocket connection closed manually", zap.String("hub", c.hub.name))
 	}
 }
 
+// CloseGracefully stops accepting new messages, writes the ones already queued
+// and sends a normal close frame before closing the connection, unlike Close
+// which discards them. It gives up on the remaining messages after timeout.
+func (c *WebSocketConnection) CloseGracefully(timeout time.Duration) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	c.closed = true
+	c.flushTimeout = timeout
+	close(c.flush)
+	c.mu.Unlock()
+
+	select {
+	case <-c.done:
+	case <-time.After(timeout):
+		c.logger.Warn("WebSocket graceful close timed out")
+	}
+	c.conn.Close()
+	c.logger.Info("WebSocket connection closed gracefully", zap.String("hub", c.hub.name))
+}
+
 func (h *WebSocketHub) Broadcast(message []byte) {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
@@ -311,6 +547,320 @@ func (h *WebSocketHub) Broadcast(message []byte) {
 	}
 }
 
+// BroadcastCount sends message to every connection in the hub and returns how
+// many connections it was queued to. Unlike Broadcast it doesn't go through the
+// hub's run loop, so the count is known when it returns. A queued message may
+// still be lost if the connection closes before writing it: the count means
+// "queued", not "acknowledged".
+func (h *WebSocketHub) BroadcastCount(message []byte) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.historySize > 0 {
+		h.recordLocked(message)
+	}
+
+	count := 0
+	for _, conn := range h.connections {
+		if conn.trySend(message) {
+			count++
+		}
+	}
+	return count
+}
+
+// Use adds middlewares wrapping the handler of every message received by the
+// hub's connections, in the order they are registered. Panics in middlewares
+// are recovered like panics in the handler.
+func (h *WebSocketHub) Use(middlewares ...WSMiddleware) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.middlewares = append(h.middlewares, middlewares...)
+}
+
+// wrap applies the hub middlewares to handler so that the first one runs first.
+func (h *WebSocketHub) wrap(handler WebSocketHandler) WebSocketHandler {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for i := len(h.middlewares) - 1; i >= 0; i-- {
+		handler = h.middlewares[i](handler)
+	}
+	return handler
+}
+
+// Events returns the channel of connection and disconnection events of the
+// hub, for pushing presence to other systems. The channel is shared by every
+// caller and buffered: when the subscriber doesn't keep up, new events are
+// dropped rather than stalling the hub.
+func (h *WebSocketHub) Events() <-chan HubEvent {
+	return h.events
+}
+
+func (h *WebSocketHub) emit(eventType HubEventType, connID string) {
+	select {
+	case h.events <- HubEvent{Type: eventType, ConnID: connID, Time: time.Now()}:
+	default:
+		h.logger.Debug("WebSocket hub event dropped", zap.String("conn_id", connID))
+	}
+}
+
+// broadcastThrottle holds the latest message of a BroadcastThrottled key
+// waiting for the end of the interval.
+type broadcastThrottle struct {
+	pending []byte
+}
+
+// BroadcastThrottled broadcasts message at most once per interval for the
+// given key, for high-frequency updates such as typing indicators or cursor
+// positions. The first message of a key is sent right away; messages received
+// during the interval replace each other and only the last one is sent when it
+// ends (last write wins), so clients always get the latest state.
+func (h *WebSocketHub) BroadcastThrottled(key string, message []byte, interval time.Duration) {
+	h.throttleMu.Lock()
+	defer h.throttleMu.Unlock()
+
+	if throttle, ok := h.throttles[key]; ok {
+		throttle.pending = message
+		return
+	}
+
+	if h.throttles == nil {
+		h.throttles = make(map[string]*broadcastThrottle)
+	}
+	throttle := &broadcastThrottle{}
+	h.throttles[key] = throttle
+	h.Broadcast(message)
+
+	var flush func()
+	flush = func() {
+		h.throttleMu.Lock()
+		message := throttle.pending
+		throttle.pending = nil
+		if message == nil {
+			// Nothing arrived during the interval, the next message is sent right away
+			delete(h.throttles, key)
+		} else {
+			time.AfterFunc(interval, flush)
+		}
+		h.throttleMu.Unlock()
+
+		if message != nil {
+			h.Broadcast(message)
+		}
+	}
+	time.AfterFunc(interval, flush)
+}
+
+// History returns the buffered broadcast messages, oldest first. It is empty
+// unless WebSocketConfig.HistorySize is set.
+func (h *WebSocketHub) History() [][]byte {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.historyLocked()
+}
+
+func (h *WebSocketHub) historyLocked() [][]byte {
+	if len(h.history) < h.historySize {
+		return append([][]byte(nil), h.history...)
+	}
+	// The buffer is full, the oldest message is at historyNext
+	messages := make([][]byte, 0, len(h.history))
+	messages = append(messages, h.history[h.historyNext:]...)
+	return append(messages, h.history[:h.historyNext]...)
+}
+
+func (h *WebSocketHub) recordLocked(message []byte) {
+	if len(h.history) < h.historySize {
+		h.history = append(h.history, message)
+		return
+	}
+	h.history[h.historyNext] = message
+	h.historyNext = (h.historyNext + 1) % h.historySize
+}
+
+// ConnInfo describes a hub connection to targeting callbacks such as BroadcastFunc.
+type ConnInfo struct {
+	ID      string
+	Hub     string
+	Request *http.Request
+	Tags    []string
+}
+
+func (c *WebSocketConnection) info() ConnInfo {
+	return ConnInfo{
+		ID:      c.id,
+		Hub:     c.hub.name,
+		Request: c.request,
+		Tags:    c.Tags(),
+	}
+}
+
+// Tag adds tags to the connection, e.g. "user:42", for BroadcastToTag and
+// DisconnectByTag.
+func (c *WebSocketConnection) Tag(tags ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tags == nil {
+		c.tags = make(map[string]struct{}, len(tags))
+	}
+	for _, tag := range tags {
+		c.tags[tag] = struct{}{}
+	}
+}
+
+// Untag removes tags from the connection.
+func (c *WebSocketConnection) Untag(tags ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, tag := range tags {
+		delete(c.tags, tag)
+	}
+}
+
+func (c *WebSocketConnection) HasTag(tag string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok := c.tags[tag]
+	return ok
+}
+
+// Tags returns the connection's tags, sorted.
+func (c *WebSocketConnection) Tags() []string {
+	c.mu.RLock()
+	tags := make([]string, 0, len(c.tags))
+	for tag := range c.tags {
+		tags = append(tags, tag)
+	}
+	c.mu.RUnlock()
+	sort.Strings(tags)
+	return tags
+}
+
+// BroadcastFunc sends message to every connection for which fn returns true.
+func (h *WebSocketHub) BroadcastFunc(fn func(conn ConnInfo) bool, message []byte) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, conn := range h.connections {
+		if fn(conn.info()) {
+			conn.Send(message)
+		}
+	}
+}
+
+// BroadcastToTag sends message to every connection tagged with tag, e.g. all
+// the devices of a user.
+func (h *WebSocketHub) BroadcastToTag(tag string, message []byte) {
+	for _, conn := range h.connectionsWithTag(tag) {
+		conn.Send(message)
+	}
+}
+
+// DisconnectByTag closes every connection tagged with tag like Disconnect and
+// returns how many were closed.
+func (h *WebSocketHub) DisconnectByTag(tag string, code int, reason string) int {
+	closed := 0
+	for _, conn := range h.connectionsWithTag(tag) {
+		if h.Disconnect(conn.id, code, reason) {
+			closed++
+		}
+	}
+	return closed
+}
+
+func (h *WebSocketHub) connectionsWithTag(tag string) []*WebSocketConnection {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var conns []*WebSocketConnection
+	for _, conn := range h.connections {
+		if conn.HasTag(tag) {
+			conns = append(conns, conn)
+		}
+	}
+	return conns
+}
+
+// GetConnection returns the info of the connection with the given ID.
+func (h *WebSocketHub) GetConnection(connID string) (ConnInfo, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	conn, ok := h.connections[connID]
+	if !ok {
+		return ConnInfo{}, false
+	}
+	return conn.info(), true
+}
+
+// Disconnect closes the connection with the given ID, sending a close frame
+// with code and reason. It returns false if the connection doesn't exist.
+func (h *WebSocketHub) Disconnect(connID string, code int, reason string) bool {
+	h.mu.RLock()
+	conn, ok := h.connections[connID]
+	h.mu.RUnlock()
+	if !ok {
+		return false
+	}
+
+	message := websocket.FormatCloseMessage(code, reason)
+	if err := conn.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second)); err != nil {
+		conn.logger.Warn("WebSocket close frame failed", zap.Error(err))
+	}
+	conn.conn.Close()
+	h.logger.Info("WebSocket connection disconnected",
+		zap.String("conn_id", connID),
+		zap.Int("code", code),
+		zap.String("reason", reason),
+	)
+	return true
+}
+
+// CloseAll sends a close frame with the given code and reason to every
+// connection in the hub and closes them.
+func (h *WebSocketHub) CloseAll(code int, reason string) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	h.closeAll(ctx, code, reason)
+}
+
+func (h *WebSocketHub) closeAll(ctx context.Context, code int, reason string) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(time.Second)
+	}
+	message := websocket.FormatCloseMessage(code, reason)
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, conn := range h.connections {
+		if ctx.Err() != nil {
+			h.logger.Warn("WebSocket hub close interrupted", zap.Error(ctx.Err()))
+			return
+		}
+		if err := conn.conn.WriteControl(websocket.CloseMessage, message, deadline); err != nil {
+			conn.logger.Warn("WebSocket close frame failed", zap.Error(err))
+		}
+		conn.conn.Close()
+	}
+}
+
+// shutdown stops the run loop of every hub.
+func (wm *WebSocketManager) shutdown() {
+	wm.cancel()
+}
+
+// closeAll closes the connections of every hub, stopping when ctx is done.
+func (wm *WebSocketManager) closeAll(ctx context.Context, code int, reason string) {
+	wm.mu.RLock()
+	defer wm.mu.RUnlock()
+
+	for _, hub := range wm.hubs {
+		hub.closeAll(ctx, code, reason)
+	}
+}
+
 func (h *WebSocketHub) SendToConnection(connId string, message []byte) bool {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
@@ -322,12 +872,74 @@ func (h *WebSocketHub) SendToConnection(connId string, message []byte) bool {
 	return false
 }
 
+// HubStats is a snapshot of a hub's connections.
+type HubStats struct {
+	Name string
+	// Registered connections
+	Connections int
+	// Connections being upgraded or served, counted against MaxConnections
+	Active int
+	// WebSocketConfig.MaxConnections, 0 is unlimited
+	MaxConnections int
+}
+
+// Stats returns the current connection counts of the hub.
+func (h *WebSocketHub) Stats() HubStats {
+	return HubStats{
+		Name:           h.name,
+		Connections:    h.GetConnectionCount(),
+		Active:         int(h.active.Load()),
+		MaxConnections: h.maxConnections,
+	}
+}
+
 func (h *WebSocketHub) GetConnectionCount() int {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 	return len(h.connections)
 }
 
+// tokenBucket is a minimal token bucket limiter used to throttle incoming messages.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+func (b *tokenBucket) allow() bool {
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// generateConnectionID returns an unpredictable ID from 16 crypto-random bytes.
 func generateConnectionID() string {
-	return fmt.Sprintf("conn_%d_%d", time.Now().UnixNano(), rand.Int63())
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand doesn't fail on supported platforms
+		panic(fmt.Sprintf("hikari: failed to generate connection ID: %v", err))
+	}
+	return "conn_" + hex.EncodeToString(b)
 }
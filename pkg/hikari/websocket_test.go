@@ -0,0 +1,378 @@
+package hikari
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+func TestWebSocketRateLimitDropsBurst(t *testing.T) {
+	config := DefaultWebSocketConfig()
+	config.MaxMessagesPerSecond = 1
+	config.MessageBurst = 3
+
+	app := newTestApp()
+	app.WithWebSocket(config)
+
+	var handled atomic.Int32
+	app.WebSocket("/ws", "rate", func(c *WSContext) {
+		handled.Add(1)
+	})
+
+	conn := dialWS(t, startServer(t, app)+"/ws")
+	for i := 0; i < 20; i++ {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte("hi")); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	// The burst passes, the refill during the test allows one more at most
+	if got := handled.Load(); got < 3 || got > 4 {
+		t.Fatalf("handled %d messages, want the burst of 3", got)
+	}
+}
+
+func TestWebSocketKeepsPreUpgradeValues(t *testing.T) {
+	app := newTestApp()
+	app.WithWebSocket(DefaultWebSocketConfig())
+	// Message handlers must not be affected by the request timeout
+	app.SetRequestTimeout(50 * time.Millisecond)
+
+	auth := func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			c.Set("authenticated", true)
+			c.Set("username", "alice")
+			next(c)
+		}
+	}
+	app.WebSocket("/ws", "values", func(c *WSContext) {
+		c.String(fmt.Sprintf("%s %v %v", c.GetString("username"), c.GetBool("authenticated"), c.Err()))
+	}, auth)
+
+	conn := dialWS(t, startServer(t, app)+"/ws")
+	for i := 0; i < 5; i++ {
+		if i == 4 {
+			time.Sleep(100 * time.Millisecond)
+		}
+		if err := conn.WriteMessage(websocket.TextMessage, []byte("who")); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, reply, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		if got := string(reply); got != "alice true <nil>" {
+			t.Fatalf("message %d: got %q, want the pre-upgrade values", i, got)
+		}
+	}
+}
+
+func TestConcurrentConnectionIDsAreUnique(t *testing.T) {
+	app := newTestApp()
+	app.WithWebSocket(DefaultWebSocketConfig())
+	app.WebSocket("/ws", "ids", func(c *WSContext) {
+		c.String(c.GetConnectionID())
+	})
+	url := startServer(t, app) + "/ws"
+
+	const clients = 50
+	ids := make(chan string, clients)
+	var wg sync.WaitGroup
+	for i := 0; i < clients; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+			if err != nil {
+				t.Errorf("dial: %v", err)
+				return
+			}
+			defer conn.Close()
+			conn.WriteMessage(websocket.TextMessage, []byte("id"))
+			conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+			_, id, err := conn.ReadMessage()
+			if err != nil {
+				t.Errorf("read: %v", err)
+				return
+			}
+			ids <- string(id)
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[string]bool)
+	for id := range ids {
+		if seen[id] {
+			t.Fatalf("duplicate connection ID %s", id)
+		}
+		seen[id] = true
+	}
+	if len(seen) != clients {
+		t.Fatalf("got %d IDs, want %d", len(seen), clients)
+	}
+}
+
+func TestHubRegisterReplacesDuplicateID(t *testing.T) {
+	wm := NewWebSocketManager(DefaultWebSocketConfig(), zap.NewNop())
+	hub := wm.RegisterHub("dup")
+	defer wm.RemoveHub("dup")
+
+	first := &WebSocketConnection{id: "conn_same", hub: hub, logger: zap.NewNop()}
+	second := &WebSocketConnection{id: "conn_same", hub: hub, logger: zap.NewNop()}
+	hub.register <- first
+	hub.register <- second
+
+	waitFor(t, func() bool { return hub.GetConnectionCount() == 2 })
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+	if hub.connections["conn_same"] != first || hub.connections[second.id] != second {
+		t.Fatalf("second connection replaced the first one")
+	}
+}
+
+func TestHubMaxConnections(t *testing.T) {
+	config := DefaultWebSocketConfig()
+	config.MaxConnections = 2
+
+	app := newTestApp()
+	app.WithWebSocket(config)
+	app.WebSocket("/ws", "limited", func(c *WSContext) {})
+	url := startServer(t, app) + "/ws"
+	hub, _ := app.GetWebSocketHub("limited")
+
+	first := dialWS(t, url)
+	dialWS(t, url)
+	waitFor(t, func() bool { return hub.Stats().Connections == 2 })
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err == nil {
+		t.Fatal("third connection was accepted")
+	}
+	if resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("third connection: got %v, want a 503", resp)
+	}
+
+	stats := hub.Stats()
+	if stats.Active != 2 || stats.MaxConnections != 2 || stats.Name != "limited" {
+		t.Fatalf("stats = %+v", stats)
+	}
+
+	// Closing a connection frees its slot
+	first.Close()
+	waitFor(t, func() bool { return hub.Stats().Active == 1 })
+	dialWS(t, url)
+}
+
+func TestConnContextCancelledOnClose(t *testing.T) {
+	app := newTestApp()
+	app.WithWebSocket(DefaultWebSocketConfig())
+
+	started := make(chan context.Context, 1)
+	app.WebSocket("/ws", "ctx", func(c *WSContext) {
+		started <- c.ConnContext()
+	})
+
+	conn := dialWS(t, startServer(t, app)+"/ws")
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("hi")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	var ctx context.Context
+	select {
+	case ctx = <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("handler not called")
+	}
+	if ctx.Err() != nil {
+		t.Fatal("connection context cancelled while the connection is open")
+	}
+
+	conn.Close()
+	select {
+	case <-ctx.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("connection context not cancelled after the client left")
+	}
+}
+
+func TestHubMiddlewareCountsMessages(t *testing.T) {
+	app := newTestApp()
+	app.WithWebSocket(DefaultWebSocketConfig())
+	app.WebSocket("/ws", "counted", func(c *WSContext) {
+		c.String("echo " + c.GetMessage())
+	})
+	hub, _ := app.GetWebSocketHub("counted")
+
+	var count atomic.Int64
+	hub.Use(func(next WebSocketHandler) WebSocketHandler {
+		return func(c *WSContext) {
+			count.Add(1)
+			next(c)
+		}
+	})
+	hub.Use(func(next WebSocketHandler) WebSocketHandler {
+		return func(c *WSContext) {
+			if c.GetMessage() == "boom" {
+				panic("middleware failure")
+			}
+			next(c)
+		}
+	})
+
+	conn := dialWS(t, startServer(t, app)+"/ws")
+	for _, msg := range []string{"one", "boom", "two"} {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		if msg == "boom" {
+			continue
+		}
+		// The panic of the second middleware must not close the connection
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, reply, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		if string(reply) != "echo "+msg {
+			t.Fatalf("reply = %q, want %q", reply, "echo "+msg)
+		}
+	}
+
+	waitFor(t, func() bool { return count.Load() == 3 })
+}
+
+func TestBroadcastThrottledKeepsLatest(t *testing.T) {
+	app := newTestApp()
+	app.WithWebSocket(DefaultWebSocketConfig())
+	app.WebSocket("/ws", "typing", func(c *WSContext) {})
+	hub, _ := app.GetWebSocketHub("typing")
+
+	conn := dialWS(t, startServer(t, app)+"/ws")
+	waitFor(t, func() bool { return hub.GetConnectionCount() == 1 })
+
+	// The first update goes out at once, the others within the interval
+	// collapse into the last one
+	interval := 100 * time.Millisecond
+	for i := 0; i < 10; i++ {
+		hub.BroadcastThrottled("user-1", []byte(strconv.Itoa(i)), interval)
+	}
+
+	var got []string
+	conn.SetReadDeadline(time.Now().Add(3 * interval))
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+		got = append(got, string(msg))
+	}
+
+	if len(got) != 2 || got[0] != "0" || got[1] != "9" {
+		t.Fatalf("received %v, want [0 9]", got)
+	}
+}
+
+func TestTagTargeting(t *testing.T) {
+	app := newTestApp()
+	app.WithWebSocket(DefaultWebSocketConfig())
+	app.WebSocket("/ws", "tags", func(c *WSContext) {
+		c.Tag(strings.Fields(c.GetMessage())...)
+		c.String("tagged")
+	})
+	hub, _ := app.GetWebSocketHub("tags")
+	url := startServer(t, app) + "/ws"
+
+	read := func(conn *websocket.Conn) string {
+		t.Helper()
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		return string(msg)
+	}
+	dialTagged := func(tags string) *websocket.Conn {
+		conn := dialWS(t, url)
+		conn.WriteMessage(websocket.TextMessage, []byte(tags))
+		if msg := read(conn); msg != "tagged" {
+			t.Fatalf("reply = %q", msg)
+		}
+		return conn
+	}
+
+	phone := dialTagged("user:1 role:admin")
+	laptop := dialTagged("user:1")
+	guest := dialTagged("user:2 role:guest")
+
+	// Each client's next message shows which broadcasts reached it
+	hub.BroadcastToTag("user:1", []byte("for user 1"))
+	hub.BroadcastToTag("role:admin", []byte("for admins"))
+	hub.BroadcastToTag("role:guest", []byte("for guests"))
+
+	if msg := read(phone); msg != "for user 1" {
+		t.Fatalf("phone got %q", msg)
+	}
+	if msg := read(phone); msg != "for admins" {
+		t.Fatalf("phone got %q", msg)
+	}
+	if msg := read(laptop); msg != "for user 1" {
+		t.Fatalf("laptop got %q", msg)
+	}
+	if msg := read(guest); msg != "for guests" {
+		t.Fatalf("guest got %q", msg)
+	}
+
+	if n := hub.DisconnectByTag("role:guest", websocket.ClosePolicyViolation, "guests not allowed"); n != 1 {
+		t.Fatalf("DisconnectByTag closed %d connections, want 1", n)
+	}
+	guest.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, _, err := guest.ReadMessage(); !websocket.IsCloseError(err, websocket.ClosePolicyViolation) {
+		t.Fatalf("guest read = %v, want a policy violation close", err)
+	}
+
+	// The laptop isn't an admin, so it still gets only user 1 messages
+	hub.BroadcastToTag("role:admin", []byte("admins again"))
+	hub.BroadcastToTag("user:1", []byte("user 1 again"))
+	if msg := read(laptop); msg != "user 1 again" {
+		t.Fatalf("laptop got %q", msg)
+	}
+}
+
+func TestSlowClientIsDisconnectedWithoutPanic(t *testing.T) {
+	app := newTestApp()
+	app.WithWebSocket(DefaultWebSocketConfig())
+	app.WebSocket("/ws", "slow", func(c *WSContext) {})
+	hub, _ := app.GetWebSocketHub("slow")
+
+	// The client never reads, so once the socket buffers are full the
+	// connection's send buffer fills up too
+	dialWS(t, startServer(t, app)+"/ws")
+	waitFor(t, func() bool { return hub.GetConnectionCount() == 1 })
+
+	message := make([]byte, 1<<20)
+	for i := 0; i < 400 && hub.GetConnectionCount() == 1; i++ {
+		hub.BroadcastCount(message)
+		hub.BroadcastFunc(func(ConnInfo) bool { return true }, message)
+		hub.Broadcast(message)
+	}
+
+	waitFor(t, func() bool { return hub.GetConnectionCount() == 0 })
+	// The hub keeps running after removing the connection
+	hub.Broadcast([]byte("still alive"))
+	hub.BroadcastToTag("none", []byte("still alive"))
+	if n := hub.BroadcastCount([]byte("still alive")); n != 0 {
+		t.Fatalf("BroadcastCount reached %d connections, want 0", n)
+	}
+}
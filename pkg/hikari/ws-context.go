@@ -1,12 +1,32 @@
 package hikari
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
+	"unicode/utf8"
 
 	"github.com/gorilla/websocket"
 )
 
+var (
+	// ErrWebSocketNotConfigured é retornado quando WithWebSocket não foi chamado
+	ErrWebSocketNotConfigured = errors.New("WebSocket not configured, call WithWebSocket() first")
+	// ErrNotTextMessage é retornado por Bind quando a mensagem não é do tipo texto
+	ErrNotTextMessage = errors.New("message is not text type")
+	// ErrInvalidJSON é retornado quando a mensagem não contém JSON válido
+	ErrInvalidJSON = errors.New("message is not valid JSON")
+	// ErrHubFull é retornado pelo upgrade quando o hub atingiu MaxConnections
+	ErrHubFull = errors.New("WebSocket hub is full")
+)
+
+// WSContext é criado para cada mensagem recebida. Todas as mensagens de uma
+// conexão compartilham o mesmo *Context da requisição de upgrade, então valores
+// definidos com c.Set por middlewares antes do upgrade continuam disponíveis em
+// cada handler. O acesso ao storage é protegido por mutex, pois os handlers de
+// mensagens rodam em goroutines concorrentes.
 type WSContext struct {
 	*Context
 	connection  *WebSocketConnection
@@ -14,6 +34,19 @@ type WSContext struct {
 	data        []byte
 }
 
+// UpgradeWebSocket faz o upgrade da requisição atual para WebSocket a partir de
+// qualquer rota, permitindo que a mesma rota sirva HTML ou WebSocket. Bloqueia
+// até a conexão ser encerrada.
+func (c *Context) UpgradeWebSocket(hubName string, handler WebSocketHandler) error {
+	if c.app == nil || c.app.wsManager == nil {
+		return ErrWebSocketNotConfigured
+	}
+	if !c.IsWebSocket() {
+		return errors.New("request is not a WebSocket upgrade")
+	}
+	return c.app.wsManager.Upgrade(c, hubName, handler)
+}
+
 // Send envia uma mensagem através desta conexão WebSocket
 func (wsc *WSContext) Send(data []byte) {
 	wsc.connection.Send(data)
@@ -49,16 +82,70 @@ func (wsc *WSContext) BroadcastJSON(v interface{}) error {
 	return nil
 }
 
+// BroadcastJSONN envia mensagem JSON para todas as conexões do hub e retorna
+// quantas conexões a receberam na fila de envio (não quantas confirmaram)
+func (wsc *WSContext) BroadcastJSONN(v interface{}) (int, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return 0, err
+	}
+	return wsc.connection.hub.BroadcastCount(data), nil
+}
+
 // BroadcastString envia string para todas as conexões do hub
 func (wsc *WSContext) BroadcastString(message string) {
 	wsc.Broadcast([]byte(message))
 }
 
+// BroadcastFunc envia mensagem para as conexões do hub em que fn retorna true
+func (wsc *WSContext) BroadcastFunc(fn func(conn ConnInfo) bool, data []byte) {
+	wsc.connection.hub.BroadcastFunc(fn, data)
+}
+
 // SendToConnection envia mensagem para uma conexão específica do hub
 func (wsc *WSContext) SendToConnection(connID string, data []byte) bool {
 	return wsc.connection.hub.SendToConnection(connID, data)
 }
 
+// GetConnection retorna as informações de uma conexão do hub
+func (wsc *WSContext) GetConnection(connID string) (ConnInfo, bool) {
+	return wsc.connection.hub.GetConnection(connID)
+}
+
+// Disconnect encerra uma conexão do hub enviando o código e o motivo do fechamento
+func (wsc *WSContext) Disconnect(connID string, code int, reason string) bool {
+	return wsc.connection.hub.Disconnect(connID, code, reason)
+}
+
+// CloseGracefully fecha esta conexão depois de enviar as mensagens já
+// enfileiradas, como uma mensagem de despedida, esperando no máximo timeout
+func (wsc *WSContext) CloseGracefully(timeout time.Duration) {
+	wsc.connection.CloseGracefully(timeout)
+}
+
+// ConnContext retorna o contexto da conexão, cancelado quando ela é encerrada
+// ou o hub é desligado. Use-o em vez do contexto HTTP em trabalhos iniciados
+// pelo handler que devem parar junto com a conexão
+func (wsc *WSContext) ConnContext() context.Context {
+	return wsc.connection.ctx
+}
+
+// Tag adiciona tags a esta conexão (ex.: "user:42", "role:admin"), usadas por
+// BroadcastToTag e DisconnectByTag do hub
+func (wsc *WSContext) Tag(tags ...string) {
+	wsc.connection.Tag(tags...)
+}
+
+// Untag remove tags desta conexão
+func (wsc *WSContext) Untag(tags ...string) {
+	wsc.connection.Untag(tags...)
+}
+
+// BroadcastToTag envia mensagem para as conexões do hub com a tag
+func (wsc *WSContext) BroadcastToTag(tag string, data []byte) {
+	wsc.connection.hub.BroadcastToTag(tag, data)
+}
+
 // GetConnectionID retorna o ID desta conexão
 func (wsc *WSContext) GetConnectionID() string {
 	return wsc.connection.id
@@ -90,7 +177,22 @@ func (wsc *WSContext) GetMessage() string {
 // BindMessage faz bind da mensagem JSON para uma estrutura
 func (wsc *WSContext) Bind(v interface{}) error {
 	if !wsc.IsTextMessage() {
-		return fmt.Errorf("message is not text type")
+		return ErrNotTextMessage
+	}
+	return wsc.unmarshal(v)
+}
+
+// BindAny faz bind da mensagem JSON aceitando mensagens de texto e binárias
+func (wsc *WSContext) BindAny(v interface{}) error {
+	return wsc.unmarshal(v)
+}
+
+func (wsc *WSContext) unmarshal(v interface{}) error {
+	if !utf8.Valid(wsc.data) {
+		return fmt.Errorf("%w: invalid UTF-8", ErrInvalidJSON)
 	}
-	return json.Unmarshal(wsc.data, v)
+	if err := json.Unmarshal(wsc.data, v); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+	return nil
 }
@@ -0,0 +1,70 @@
+package hikari
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// WSMux dispatches JSON WebSocket messages to handlers based on a type field.
+// Register it as the handler of a WebSocket route with mux.Handle:
+//
+//	mux := hikari.NewWSMux()
+//	mux.On("join", joinHandler)
+//	mux.On("message", messageHandler)
+//	app.WebSocket("/ws", "chat", mux.Handle)
+type WSMux struct {
+	// Name of the JSON field holding the message type, "type" by default
+	TypeField string
+
+	handlers       map[string]WebSocketHandler
+	defaultHandler WebSocketHandler
+	mu             sync.RWMutex
+}
+
+func NewWSMux() *WSMux {
+	return &WSMux{
+		TypeField: "type",
+		handlers:  make(map[string]WebSocketHandler),
+	}
+}
+
+// On registers the handler for messages whose type field equals msgType.
+func (m *WSMux) On(msgType string, handler WebSocketHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers[msgType] = handler
+}
+
+// Default registers the handler for messages with an unknown or missing type.
+func (m *WSMux) Default(handler WebSocketHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.defaultHandler = handler
+}
+
+// Handle dispatches the message to the matching handler.
+func (m *WSMux) Handle(c *WSContext) {
+	m.mu.RLock()
+	handler, ok := m.handlers[m.messageType(c)]
+	if !ok {
+		handler = m.defaultHandler
+	}
+	m.mu.RUnlock()
+
+	if handler != nil {
+		handler(c)
+	}
+}
+
+func (m *WSMux) messageType(c *WSContext) string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(c.data, &fields); err != nil {
+		return ""
+	}
+
+	var msgType string
+	if err := json.Unmarshal(fields[m.TypeField], &msgType); err != nil {
+		return ""
+	}
+	return msgType
+}
@@ -0,0 +1,69 @@
+// Package hikariotel provides OpenTelemetry tracing for Hikari applications.
+// It lives in its own package so the core framework doesn't depend on OTel.
+package hikariotel
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gabehamasaki/hikari/pkg/hikari"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware wraps each request in a span. Incoming trace context headers
+// (traceparent) are extracted with the global propagator and the span is stored
+// in c.Context so handlers can create child spans. The span is renamed after the
+// matched route pattern once the handler returns. WebSocket upgrades are not traced.
+func Middleware(tracer trace.Tracer) hikari.Middleware {
+	propagator := otel.GetTextMapPropagator()
+
+	return func(next hikari.HandlerFunc) hikari.HandlerFunc {
+		return func(c *hikari.Context) {
+			if c.IsWebSocket() {
+				next(c)
+				return
+			}
+
+			parent := propagator.Extract(c.Context, propagation.HeaderCarrier(c.Request.Header))
+			ctx, span := tracer.Start(parent, c.Method()+" "+c.Path(),
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", c.Method()),
+					attribute.String("url.path", c.Path()),
+				),
+			)
+			defer span.End()
+			defer func() {
+				// Record the panic and let the recovery middleware handle it
+				if r := recover(); r != nil {
+					span.RecordError(fmt.Errorf("panic: %v", r))
+					span.SetStatus(codes.Error, "panic")
+					panic(r)
+				}
+			}()
+
+			c.Context = ctx
+			next(c)
+
+			if route := c.Route(); route != "" {
+				span.SetName(c.Method() + " " + route)
+				span.SetAttributes(attribute.String("http.route", route))
+			}
+
+			status := c.GetStatus()
+			span.SetAttributes(attribute.Int("http.response.status_code", status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+		}
+	}
+}
+
+// SpanContext returns the span context of the request's current span.
+func SpanContext(c *hikari.Context) trace.SpanContext {
+	return trace.SpanContextFromContext(c.Context)
+}
@@ -0,0 +1,79 @@
+// Package hikarischema validates request bodies against JSON Schemas. It lives
+// in its own package so the core framework doesn't depend on a schema library.
+package hikarischema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gabehamasaki/hikari/pkg/hikari"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Violation is a single schema violation reported in the 422 response.
+type Violation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// Middleware validates the JSON request body against schema before calling the
+// handler, responding 422 with the list of violations when it doesn't conform.
+// The body is cached, so the handler can still Bind it. It panics if the schema
+// doesn't compile, so a broken schema fails at startup. Use it per route:
+//
+//	app.POST("/users", createUser, hikarischema.Middleware(userSchema))
+func Middleware(schema []byte) hikari.Middleware {
+	compiled, err := jsonschema.CompileString("schema.json", string(schema))
+	if err != nil {
+		panic(fmt.Sprintf("hikarischema: invalid JSON schema: %v", err))
+	}
+
+	return func(next hikari.HandlerFunc) hikari.HandlerFunc {
+		return func(c *hikari.Context) {
+			body, err := c.BodyBytes()
+			if err != nil {
+				if !errors.Is(err, hikari.ErrBodyTooLarge) {
+					c.Error(http.StatusBadRequest, err)
+				}
+				return
+			}
+
+			dec := json.NewDecoder(bytes.NewReader(body))
+			dec.UseNumber()
+			var doc any
+			if err := dec.Decode(&doc); err != nil {
+				c.Error(http.StatusBadRequest, fmt.Errorf("invalid JSON body: %w", err))
+				return
+			}
+
+			if err := compiled.Validate(doc); err != nil {
+				var validationErr *jsonschema.ValidationError
+				if !errors.As(err, &validationErr) {
+					c.Error(http.StatusInternalServerError, err)
+					return
+				}
+				c.JSON(http.StatusUnprocessableEntity, hikari.H{
+					"error":      "request body does not match schema",
+					"violations": violations(validationErr, nil),
+				})
+				return
+			}
+
+			next(c)
+		}
+	}
+}
+
+// violations flattens the validation error tree into its leaf errors.
+func violations(err *jsonschema.ValidationError, list []Violation) []Violation {
+	if len(err.Causes) == 0 {
+		return append(list, Violation{Path: err.InstanceLocation, Message: err.Message})
+	}
+	for _, cause := range err.Causes {
+		list = violations(cause, list)
+	}
+	return list
+}